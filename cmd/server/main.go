@@ -40,6 +40,14 @@ func main() {
 	}
 
 	hub := ws.NewHub()
+	switch cfg.WSBackplane {
+	case "memory":
+		// NewHub already uses an in-memory backplane.
+	case "redis":
+		hub.UseBackplane(ws.NewRedisBackplane(st.Redis))
+	default:
+		log.Fatal().Str("backplane", cfg.WSBackplane).Msg("unknown WS_BACKPLANE")
+	}
 
 	stopSweeper := ws.StartSweeper(hub, ws.SweeperConfig{
 		ConnIdleTimeout: 30 * time.Second,
@@ -48,6 +56,26 @@ func main() {
 	})
 	defer stopSweeper()
 
+	stopClaimResolver := ws.StartClaimResolver(hub, st, ws.ClaimResolverConfig{
+		Tick:  2 * time.Second,
+		Batch: 50,
+	})
+	defer stopClaimResolver()
+
+	stopRoundTimer := ws.StartRoundTimer(hub, st, ws.RoundTimerConfig{
+		Tick:  time.Second,
+		Batch: 50,
+	})
+	defer stopRoundTimer()
+
+	if cfg.HostFailoverGrace > 0 {
+		stopHostFailover := ws.StartHostFailover(hub, st, ws.HostFailoverConfig{
+			Grace: cfg.HostFailoverGrace,
+			Tick:  5 * time.Second,
+		})
+		defer stopHostFailover()
+	}
+
 	wsHandler := ws.NewHandler(hub, st, tokens)
 
 	r := httphandler.NewRouter(st, tokens, cfg.CookieSecure, cfg.CookieDomain, wsHandler)
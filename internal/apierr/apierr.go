@@ -0,0 +1,177 @@
+// Package apierr defines the stable error catalogue returned to clients over
+// both HTTP and WebSocket. Clients should branch on Code, never on Message.
+package apierr
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/storage"
+)
+
+type Code string
+
+const (
+	BadRequest     Code = "BAD_REQUEST"
+	BadJSON        Code = "BAD_JSON"
+	InvalidPayload Code = "INVALID_PAYLOAD"
+	Unauthorized   Code = "UNAUTHORIZED"
+	NotFound       Code = "NOT_FOUND"
+	RateLimited    Code = "RATE_LIMITED"
+	Internal       Code = "INTERNAL"
+
+	RoomNotFound   Code = "ROOM_NOT_FOUND"
+	MemberNotFound Code = "MEMBER_NOT_FOUND"
+	NotMember      Code = "NOT_MEMBER"
+	NotJoined      Code = "NOT_JOINED"
+	AlreadyInRoom  Code = "ALREADY_IN_ROOM"
+	NotHost        Code = "NOT_HOST"
+	CannotHost     Code = "CANNOT_HOST"
+	Banned         Code = "BANNED"
+	RoomFull       Code = "ROOM_FULL"
+
+	NoPackSelection     Code = "NO_PACK_SELECTION"
+	PackNotFound        Code = "PACK_NOT_FOUND"
+	NotEnoughCharacters Code = "NOT_ENOUGH_CHARACTERS"
+	NoPlayersConnected  Code = "NO_PLAYERS_CONNECTED"
+	RoundAlreadyActive  Code = "ROUND_ALREADY_ACTIVE"
+	NoActiveRound       Code = "NO_ACTIVE_ROUND"
+	GameAlreadyActive   Code = "GAME_ALREADY_ACTIVE"
+	NoActiveGame        Code = "NO_ACTIVE_GAME"
+
+	NotInRound         Code = "NOT_IN_ROUND"
+	AlreadyGuessed     Code = "ALREADY_GUESSED"
+	ClaimAlreadyOpen   Code = "CLAIM_ALREADY_OPEN"
+	ClaimNotFound      Code = "CLAIM_NOT_FOUND"
+	ClaimNotOpen       Code = "CLAIM_NOT_OPEN"
+	CannotVoteOwnClaim Code = "CANNOT_VOTE_OWN_CLAIM"
+	Spectating         Code = "SPECTATING"
+	NothingToUndo      Code = "NOTHING_TO_UNDO"
+)
+
+var httpStatus = map[Code]int{
+	BadRequest:     http.StatusBadRequest,
+	BadJSON:        http.StatusBadRequest,
+	InvalidPayload: http.StatusBadRequest,
+	Unauthorized:   http.StatusUnauthorized,
+	NotFound:       http.StatusNotFound,
+	RateLimited:    http.StatusTooManyRequests,
+	Internal:       http.StatusInternalServerError,
+
+	RoomNotFound:   http.StatusNotFound,
+	MemberNotFound: http.StatusNotFound,
+	NotMember:      http.StatusForbidden,
+	NotJoined:      http.StatusConflict,
+	AlreadyInRoom:  http.StatusConflict,
+	NotHost:        http.StatusForbidden,
+	CannotHost:     http.StatusConflict,
+	Banned:         http.StatusForbidden,
+	RoomFull:       http.StatusConflict,
+
+	NoPackSelection:     http.StatusConflict,
+	PackNotFound:        http.StatusNotFound,
+	NotEnoughCharacters: http.StatusConflict,
+	NoPlayersConnected:  http.StatusConflict,
+	RoundAlreadyActive:  http.StatusConflict,
+	NoActiveRound:       http.StatusConflict,
+	GameAlreadyActive:   http.StatusConflict,
+	NoActiveGame:        http.StatusConflict,
+
+	NotInRound:         http.StatusConflict,
+	AlreadyGuessed:     http.StatusConflict,
+	ClaimAlreadyOpen:   http.StatusConflict,
+	ClaimNotFound:      http.StatusNotFound,
+	ClaimNotOpen:       http.StatusConflict,
+	CannotVoteOwnClaim: http.StatusForbidden,
+	Spectating:         http.StatusForbidden,
+	NothingToUndo:      http.StatusConflict,
+}
+
+// sentinels maps storage errors to their client-facing codes.
+var sentinels = []struct {
+	err  error
+	code Code
+}{
+	{storage.ErrNotHost, NotHost},
+	{storage.ErrCannotHost, CannotHost},
+	{storage.ErrBanned, Banned},
+	{storage.ErrRoomFull, RoomFull},
+	{storage.ErrInvalidSettings, InvalidPayload},
+	{storage.ErrNoPackSelection, NoPackSelection},
+	{storage.ErrPackNotFound, PackNotFound},
+	{storage.ErrNotEnoughCharacters, NotEnoughCharacters},
+	{storage.ErrRoundAlreadyActive, RoundAlreadyActive},
+	{storage.ErrNoEligiblePlayers, NoPlayersConnected},
+	{storage.ErrNoActiveRound, NoActiveRound},
+	{storage.ErrGameAlreadyActive, GameAlreadyActive},
+	{storage.ErrNoActiveGame, NoActiveGame},
+	{storage.ErrNotInRound, NotInRound},
+	{storage.ErrAlreadyGuessed, AlreadyGuessed},
+	{storage.ErrClaimAlreadyOpen, ClaimAlreadyOpen},
+	{storage.ErrClaimNotOpen, ClaimNotOpen},
+	{storage.ErrCannotVoteOwnClaim, CannotVoteOwnClaim},
+	{storage.ErrSpectator, Spectating},
+	{storage.ErrNotMember, NotMember},
+	{storage.ErrNothingToUndo, NothingToUndo},
+}
+
+type Error struct {
+	Code      Code   `json:"code"`
+	Message   string `json:"message"`
+	Details   any    `json:"details,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
+}
+
+func (e *Error) Error() string { return string(e.Code) + ": " + e.Message }
+
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+func (e *Error) WithDetails(details any) *Error {
+	cp := *e
+	cp.Details = details
+	return &cp
+}
+
+// HTTPStatus returns the HTTP status code used for code.
+func (e *Error) HTTPStatus() int {
+	if s, ok := httpStatus[e.Code]; ok {
+		return s
+	}
+	return http.StatusInternalServerError
+}
+
+// From converts err into a catalogue error. Known storage sentinels keep their
+// message; anything else becomes INTERNAL so database details never leak.
+func From(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	for _, s := range sentinels {
+		if errors.Is(err, s.err) {
+			// Storage may wrap a sentinel with detail meant for the client.
+			return New(s.code, err.Error())
+		}
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return New(NotFound, "not found")
+	}
+	return New(Internal, "internal error")
+}
+
+// WriteHTTP writes e as a JSON error body with its mapped status code. The
+// request's X-Request-ID header, when present, is echoed as requestId.
+func WriteHTTP(w http.ResponseWriter, r *http.Request, e *Error) {
+	cp := *e
+	if r != nil {
+		cp.RequestID = r.Header.Get("X-Request-ID")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(cp.HTTPStatus())
+	_ = json.NewEncoder(w).Encode(&cp)
+}
@@ -0,0 +1,55 @@
+// Package authz decides who may read or change a room. The HTTP handlers and
+// the WebSocket handler share it so both transports enforce the same rules.
+package authz
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/apierr"
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/storage"
+)
+
+type Level int
+
+// RoomStore is the part of the storage layer authorization reads from.
+type RoomStore interface {
+	GetRoomByCode(ctx context.Context, code string) (*storage.Room, error)
+	GetRoomMember(ctx context.Context, roomID, userID string) (*storage.RoomMember, error)
+}
+
+var _ RoomStore = (*storage.Storage)(nil)
+
+const (
+	Member Level = iota // may read room state
+	Owner               // may change the room
+)
+
+// Room loads the room with code and checks that userID has at least level
+// access to it. Denials are returned as *apierr.Error (ROOM_NOT_FOUND,
+// NOT_MEMBER or NOT_HOST).
+func Room(ctx context.Context, store RoomStore, code, userID string, level Level) (*storage.Room, error) {
+	room, err := store.GetRoomByCode(ctx, code)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, apierr.New(apierr.RoomNotFound, "room not found")
+		}
+		return nil, err
+	}
+
+	if room.OwnerUserID == userID {
+		return room, nil
+	}
+	if level == Owner {
+		return nil, apierr.New(apierr.NotHost, "host only")
+	}
+
+	if _, err := store.GetRoomMember(ctx, room.ID, userID); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, apierr.New(apierr.NotMember, "not a member of this room")
+		}
+		return nil, err
+	}
+	return room, nil
+}
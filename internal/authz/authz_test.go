@@ -0,0 +1,85 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/apierr"
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/storage"
+)
+
+type fakeStore struct {
+	rooms   map[string]*storage.Room
+	members map[string]bool // roomID + "/" + userID
+	err     error
+}
+
+func (f *fakeStore) GetRoomByCode(_ context.Context, code string) (*storage.Room, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	r, ok := f.rooms[code]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return r, nil
+}
+
+func (f *fakeStore) GetRoomMember(_ context.Context, roomID, userID string) (*storage.RoomMember, error) {
+	if !f.members[roomID+"/"+userID] {
+		return nil, pgx.ErrNoRows
+	}
+	return &storage.RoomMember{UserID: userID}, nil
+}
+
+func TestRoom(t *testing.T) {
+	store := &fakeStore{
+		rooms:   map[string]*storage.Room{"ABCD": {ID: "room-1", Code: "ABCD", OwnerUserID: "owner"}},
+		members: map[string]bool{"room-1/member": true},
+	}
+
+	tests := []struct {
+		name   string
+		code   string
+		userID string
+		level  Level
+		want   apierr.Code // empty when access is granted
+	}{
+		{"owner reads", "ABCD", "owner", Member, ""},
+		{"owner changes", "ABCD", "owner", Owner, ""},
+		{"member reads", "ABCD", "member", Member, ""},
+		{"member changes", "ABCD", "member", Owner, apierr.NotHost},
+		{"stranger reads", "ABCD", "stranger", Member, apierr.NotMember},
+		{"stranger changes", "ABCD", "stranger", Owner, apierr.NotHost},
+		{"unknown room", "ZZZZ", "owner", Member, apierr.RoomNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			room, err := Room(context.Background(), store, tt.code, tt.userID, tt.level)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("Room() error = %v, want access", err)
+				}
+				if room.ID != "room-1" {
+					t.Fatalf("Room() = %q, want room-1", room.ID)
+				}
+				return
+			}
+			var e *apierr.Error
+			if !errors.As(err, &e) || e.Code != tt.want {
+				t.Fatalf("Room() error = %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoomStoreError(t *testing.T) {
+	boom := errors.New("connection refused")
+	_, err := Room(context.Background(), &fakeStore{err: boom}, "ABCD", "owner", Member)
+	if !errors.Is(err, boom) {
+		t.Fatalf("Room() error = %v, want %v", err, boom)
+	}
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"path/filepath"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
@@ -18,6 +19,9 @@ type Config struct {
 	JWTSecret    string `envconfig:"JWT_SECRET" required:"true"`
 	CookieSecure bool   `envconfig:"COOKIE_SECURE" default:"false"`
 	CookieDomain string `envconfig:"COOKIE_DOMAIN" default:""`
+
+	WSBackplane       string        `envconfig:"WS_BACKPLANE" default:"memory"`
+	HostFailoverGrace time.Duration `envconfig:"HOST_FAILOVER_GRACE" default:"0s"`
 }
 
 func Load() (Config, error) {
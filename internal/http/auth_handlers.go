@@ -10,6 +10,7 @@ import (
 
 	"github.com/rs/zerolog/log"
 
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/apierr"
 	"github.com/JsotoSoftware/guess-who-game-backend/internal/auth"
 	"github.com/JsotoSoftware/guess-who-game-backend/internal/storage"
 )
@@ -43,7 +44,7 @@ func (h *AuthHandlers) Guest(w http.ResponseWriter, r *http.Request) {
 
 	refreshToken, err := auth.NewRefreshToken()
 	if err != nil {
-		http.Error(w, "failed", http.StatusInternalServerError)
+		writeError(w, r, apierr.New(apierr.Internal, "failed"))
 		return
 	}
 	hash := auth.HashToken(refreshToken)
@@ -58,7 +59,7 @@ func (h *AuthHandlers) Guest(w http.ResponseWriter, r *http.Request) {
 	userID, _, err := h.Store.CreateGuestUserAndSession(ctx, hash, expiresAt, ua, ip)
 	if err != nil {
 		log.Error().Err(err).Msg("create guest failed")
-		http.Error(w, "failed", http.StatusInternalServerError)
+		writeError(w, r, apierr.New(apierr.Internal, "failed"))
 		return
 	}
 
@@ -66,7 +67,7 @@ func (h *AuthHandlers) Guest(w http.ResponseWriter, r *http.Request) {
 
 	access, accessExp, err := h.Tokens.NewAccessToken(userID, 15*time.Minute)
 	if err != nil {
-		http.Error(w, "failed", http.StatusInternalServerError)
+		writeError(w, r, apierr.New(apierr.Internal, "failed"))
 		return
 	}
 
@@ -76,7 +77,7 @@ func (h *AuthHandlers) Guest(w http.ResponseWriter, r *http.Request) {
 func (h *AuthHandlers) Refresh(w http.ResponseWriter, r *http.Request) {
 	c, err := r.Cookie(auth.RefreshCookieName)
 	if err != nil || c.Value == "" {
-		http.Error(w, "missing refresh", http.StatusUnauthorized)
+		writeError(w, r, apierr.New(apierr.Unauthorized, "missing refresh"))
 		return
 	}
 
@@ -85,7 +86,7 @@ func (h *AuthHandlers) Refresh(w http.ResponseWriter, r *http.Request) {
 
 	newToken, err := auth.NewRefreshToken()
 	if err != nil {
-		http.Error(w, "failed", http.StatusInternalServerError)
+		writeError(w, r, apierr.New(apierr.Internal, "failed"))
 		return
 	}
 
@@ -100,7 +101,7 @@ func (h *AuthHandlers) Refresh(w http.ResponseWriter, r *http.Request) {
 
 	_, userID, err := h.Store.RotateRefreshSession(ctx, oldHash, newHash, newExpires, ua, ip)
 	if err != nil {
-		http.Error(w, "invalid refresh", http.StatusUnauthorized)
+		writeError(w, r, apierr.New(apierr.Unauthorized, "invalid refresh"))
 		return
 	}
 
@@ -108,7 +109,7 @@ func (h *AuthHandlers) Refresh(w http.ResponseWriter, r *http.Request) {
 
 	access, accessExp, err := h.Tokens.NewAccessToken(userID, 15*time.Minute)
 	if err != nil {
-		http.Error(w, "failed", http.StatusInternalServerError)
+		writeError(w, r, apierr.New(apierr.Internal, "failed"))
 		return
 	}
 
@@ -153,6 +154,10 @@ func setRefreshCookie(w http.ResponseWriter, token string, exp time.Time, secure
 	})
 }
 
+func writeError(w http.ResponseWriter, r *http.Request, e *apierr.Error) {
+	apierr.WriteHTTP(w, r, e)
+}
+
 func writeJSON(w http.ResponseWriter, v any) {
 	w.Header().Set("Content-Type", "application/json")
 	enc := json.NewEncoder(w)
@@ -0,0 +1,35 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/apierr"
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/authz"
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/storage"
+)
+
+// roomStoreFor returns what authorizeRoom checks access against. Tests replace
+// it to authorize without a database.
+var roomStoreFor = func(store *storage.Storage) authz.RoomStore { return store }
+
+// authorizeRoom checks the authenticated user's access to the room with code and
+// writes the error response when it is denied.
+func authorizeRoom(ctx context.Context, w http.ResponseWriter, r *http.Request, store *storage.Storage, code string, level authz.Level) (*storage.Room, bool) {
+	userID, ok := UserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		writeError(w, r, apierr.New(apierr.Unauthorized, "unauthorized"))
+		return nil, false
+	}
+	if code == "" {
+		writeError(w, r, apierr.New(apierr.BadRequest, "missing code"))
+		return nil, false
+	}
+
+	room, err := authz.Room(ctx, roomStoreFor(store), code, userID, level)
+	if err != nil {
+		writeError(w, r, apierr.From(err))
+		return nil, false
+	}
+	return room, true
+}
@@ -0,0 +1,98 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/apierr"
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/auth"
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/authz"
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/storage"
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/ws"
+)
+
+type fakeRoomStore struct{}
+
+func (fakeRoomStore) GetRoomByCode(_ context.Context, code string) (*storage.Room, error) {
+	if code != "ABCD" {
+		return nil, pgx.ErrNoRows
+	}
+	return &storage.Room{ID: "room-1", Code: "ABCD", OwnerUserID: "owner"}, nil
+}
+
+func (fakeRoomStore) GetRoomMember(_ context.Context, roomID, userID string) (*storage.RoomMember, error) {
+	if roomID != "room-1" || userID != "member" {
+		return nil, pgx.ErrNoRows
+	}
+	return &storage.RoomMember{UserID: userID}, nil
+}
+
+// TestRoomRoutesDenyAccess checks that every room route refuses users without
+// the access it needs before touching the database.
+func TestRoomRoutesDenyAccess(t *testing.T) {
+	prev := roomStoreFor
+	roomStoreFor = func(*storage.Storage) authz.RoomStore { return fakeRoomStore{} }
+	t.Cleanup(func() { roomStoreFor = prev })
+
+	tokens, err := auth.NewTokenMaker("test-secret-test-secret-test-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := NewRouter(nil, tokens, false, "", ws.NewHandler(ws.NewHub(), nil, tokens))
+
+	tests := []struct {
+		user   string
+		method string
+		path   string
+		body   string
+		want   apierr.Code
+		status int
+	}{
+		{"stranger", "GET", "/v1/rooms/members?code=ABCD", "", apierr.NotMember, http.StatusForbidden},
+		{"stranger", "GET", "/v1/rooms/state?code=ABCD", "", apierr.NotMember, http.StatusForbidden},
+		{"stranger", "GET", "/v1/rooms/packs?code=ABCD", "", apierr.NotMember, http.StatusForbidden},
+		{"stranger", "GET", "/v1/rooms/ABCD/scores/history", "", apierr.NotMember, http.StatusForbidden},
+		{"stranger", "GET", "/v1/rooms/ABCD/settings", "", apierr.NotMember, http.StatusForbidden},
+		{"stranger", "GET", "/v1/rooms/ABCD/rounds", "", apierr.NotMember, http.StatusForbidden},
+		{"stranger", "GET", "/v1/rooms/ABCD/rounds/some-round", "", apierr.NotMember, http.StatusForbidden},
+		{"stranger", "GET", "/v1/rooms/ABCD/summary", "", apierr.NotMember, http.StatusForbidden},
+		{"stranger", "GET", "/v1/rooms/ABCD/pool", "", apierr.NotMember, http.StatusForbidden},
+		{"stranger", "GET", "/v1/rooms/ZZZZ/settings", "", apierr.RoomNotFound, http.StatusNotFound},
+
+		{"member", "POST", "/v1/rooms/packs", `{"code":"ABCD","packSlugs":["animals"]}`, apierr.NotHost, http.StatusForbidden},
+		{"member", "PUT", "/v1/rooms/ABCD/settings", `{"maxPlayers":4}`, apierr.NotHost, http.StatusForbidden},
+		{"stranger", "POST", "/v1/rooms/packs", `{"code":"ABCD","packSlugs":["animals"]}`, apierr.NotHost, http.StatusForbidden},
+		{"stranger", "PUT", "/v1/rooms/ABCD/settings", `{"maxPlayers":4}`, apierr.NotHost, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.user+" "+tt.method+" "+tt.path, func(t *testing.T) {
+			token, _, err := tokens.NewAccessToken(tt.user, time.Minute)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.status, rec.Body)
+			}
+			var got apierr.Error
+			if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+				t.Fatalf("decode error body: %v", err)
+			}
+			if got.Code != tt.want {
+				t.Fatalf("code = %s, want %s", got.Code, tt.want)
+			}
+		})
+	}
+}
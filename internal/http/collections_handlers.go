@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/apierr"
 	"github.com/JsotoSoftware/guess-who-game-backend/internal/storage"
 	"github.com/go-chi/chi/v5"
 )
@@ -27,7 +28,7 @@ func (h *CollectionsHandlers) List(w http.ResponseWriter, r *http.Request) {
 
 	items, err := h.Store.ListCollections(ctx, lang)
 	if err != nil {
-		http.Error(w, "failed", http.StatusInternalServerError)
+		writeError(w, r, apierr.New(apierr.Internal, "failed"))
 		return
 	}
 	writeJSON(w, items)
@@ -37,7 +38,7 @@ func (h *CollectionsHandlers) Packs(w http.ResponseWriter, r *http.Request) {
 	lang := getLang(r)
 	slug := chi.URLParam(r, "slug")
 	if slug == "" {
-		http.Error(w, "missing slug", http.StatusBadRequest)
+		writeError(w, r, apierr.New(apierr.BadRequest, "missing slug"))
 		return
 	}
 
@@ -46,7 +47,7 @@ func (h *CollectionsHandlers) Packs(w http.ResponseWriter, r *http.Request) {
 
 	packs, err := h.Store.ListPacksForCollection(ctx, slug, lang)
 	if err != nil {
-		http.Error(w, "failed", http.StatusInternalServerError)
+		writeError(w, r, apierr.New(apierr.Internal, "failed"))
 		return
 	}
 	writeJSON(w, packs)
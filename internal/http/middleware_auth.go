@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/apierr"
 	"github.com/JsotoSoftware/guess-who-game-backend/internal/auth"
 )
 
@@ -23,14 +24,14 @@ func RequireAuth(tokens *auth.TokenMaker) func(http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			h := r.Header.Get("Authorization")
 			if h == "" || !strings.HasPrefix(strings.ToLower(h), "bearer ") {
-				http.Error(w, "missing bearer token", http.StatusUnauthorized)
+				writeError(w, r, apierr.New(apierr.Unauthorized, "missing bearer token"))
 				return
 			}
 			raw := strings.TrimSpace(h[7:])
 
 			claims, err := tokens.ParseAccessToken(raw)
 			if err != nil || claims.UserID == "" {
-				http.Error(w, "invalid token", http.StatusUnauthorized)
+				writeError(w, r, apierr.New(apierr.Unauthorized, "invalid token"))
 				return
 			}
 
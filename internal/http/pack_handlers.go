@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/apierr"
 	"github.com/JsotoSoftware/guess-who-game-backend/internal/storage"
 	"github.com/go-chi/chi/v5"
 	"github.com/jackc/pgx/v5"
@@ -37,7 +38,7 @@ func (h *PacksHandlers) List(w http.ResponseWriter, r *http.Request) {
 
 	packs, err := h.Store.ListPacks(ctx, lang)
 	if err != nil {
-		http.Error(w, "failed", http.StatusInternalServerError)
+		writeError(w, r, apierr.New(apierr.Internal, "failed"))
 		return
 	}
 	writeJSON(w, packs)
@@ -47,7 +48,7 @@ func (h *PacksHandlers) Get(w http.ResponseWriter, r *http.Request) {
 	lang := getLang(r)
 	slug := chi.URLParam(r, "slug")
 	if slug == "" {
-		http.Error(w, "missing slug", http.StatusBadRequest)
+		writeError(w, r, apierr.New(apierr.BadRequest, "missing slug"))
 		return
 	}
 
@@ -57,10 +58,10 @@ func (h *PacksHandlers) Get(w http.ResponseWriter, r *http.Request) {
 	p, err := h.Store.GetPackBySlug(ctx, slug, lang)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			http.Error(w, "not found", http.StatusNotFound)
+			writeError(w, r, apierr.New(apierr.PackNotFound, "pack not found"))
 			return
 		}
-		http.Error(w, "failed", http.StatusInternalServerError)
+		writeError(w, r, apierr.New(apierr.Internal, "failed"))
 		return
 	}
 	writeJSON(w, p)
@@ -70,7 +71,7 @@ func (h *PacksHandlers) Characters(w http.ResponseWriter, r *http.Request) {
 	lang := getLang(r)
 	slug := chi.URLParam(r, "slug")
 	if slug == "" {
-		http.Error(w, "missing slug", http.StatusBadRequest)
+		writeError(w, r, apierr.New(apierr.BadRequest, "missing slug"))
 		return
 	}
 
@@ -82,7 +83,7 @@ func (h *PacksHandlers) Characters(w http.ResponseWriter, r *http.Request) {
 
 	items, err := h.Store.ListCharactersByPackSlug(ctx, slug, lang, limit, offset)
 	if err != nil {
-		http.Error(w, "failed", http.StatusInternalServerError)
+		writeError(w, r, apierr.New(apierr.Internal, "failed"))
 		return
 	}
 	writeJSON(w, items)
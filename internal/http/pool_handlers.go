@@ -0,0 +1,75 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/apierr"
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/authz"
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/storage"
+)
+
+type PoolHandlers struct {
+	Store  *storage.Storage
+	Events RoomEvents
+}
+
+func NewPoolHandlers(store *storage.Storage, events RoomEvents) *PoolHandlers {
+	return &PoolHandlers{
+		Store:  store,
+		Events: events,
+	}
+}
+
+// Get reports how many unused characters the selected packs have left against
+// how many connected players would need one, like the pool in room:presence.
+// lang and translatedOnly default to the room's settings.
+func (h *PoolHandlers) Get(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	room, ok := authorizeRoom(ctx, w, r, h.Store, chi.URLParam(r, "code"), authz.Member)
+	if !ok {
+		return
+	}
+
+	settings, err := h.Store.GetRoomSettings(ctx, room.ID)
+	if err != nil {
+		writeError(w, r, apierr.New(apierr.Internal, "failed"))
+		return
+	}
+
+	lang := r.URL.Query().Get("lang")
+	if lang == "" {
+		lang = settings.Lang
+	}
+	translatedOnly := settings.TranslatedOnly
+	if v := r.URL.Query().Get("translatedOnly"); v != "" {
+		if translatedOnly, err = strconv.ParseBool(v); err != nil {
+			writeError(w, r, apierr.New(apierr.BadRequest, "translatedOnly must be a boolean"))
+			return
+		}
+	}
+
+	pool, err := h.Store.GetRoomPool(ctx, room.ID, lang, translatedOnly)
+	if err != nil {
+		writeError(w, r, apierr.New(apierr.Internal, "failed"))
+		return
+	}
+	players, err := h.Store.CountRoundPlayers(ctx, room.ID, settings, h.Events.ConnectedUsers(ctx, room.Code))
+	if err != nil {
+		writeError(w, r, apierr.New(apierr.Internal, "failed"))
+		return
+	}
+
+	writeJSON(w, map[string]any{
+		"code":    room.Code,
+		"pool":    pool,
+		"players": players,
+		"enough":  pool.Remaining >= players,
+	})
+}
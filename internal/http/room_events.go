@@ -0,0 +1,18 @@
+package http
+
+import (
+	"context"
+
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/storage"
+)
+
+// RoomEvents receives the room changes made through the HTTP API so they can be
+// pushed to connected clients. *ws.Handler implements it.
+type RoomEvents interface {
+	PacksChanged(ctx context.Context, code string, packSlugs []string)
+	MemberJoined(ctx context.Context, code, roomID string, m storage.RoomMember)
+	SettingsChanged(ctx context.Context, code, roomID string, settings storage.RoomSettings)
+
+	// ConnectedUsers returns the users with a live socket in the room.
+	ConnectedUsers(ctx context.Context, code string) []string
+}
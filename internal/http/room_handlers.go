@@ -12,12 +12,15 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/rs/zerolog/log"
 
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/apierr"
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/authz"
 	"github.com/JsotoSoftware/guess-who-game-backend/internal/domain"
 	"github.com/JsotoSoftware/guess-who-game-backend/internal/storage"
 )
 
 type RoomsHandlers struct {
-	Store *storage.Storage
+	Store  *storage.Storage
+	Events RoomEvents
 }
 
 type createRoomResp struct {
@@ -29,16 +32,17 @@ type joinRoomReq struct {
 	DisplayName string `json:"displayName"`
 }
 
-func NewRoomHandlers(store *storage.Storage) *RoomsHandlers {
+func NewRoomHandlers(store *storage.Storage, events RoomEvents) *RoomsHandlers {
 	return &RoomsHandlers{
-		Store: store,
+		Store:  store,
+		Events: events,
 	}
 }
 
 func (h *RoomsHandlers) Create(w http.ResponseWriter, r *http.Request) {
 	userID, ok := UserIDFromContext(r.Context())
 	if !ok {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		writeError(w, r, apierr.New(apierr.Unauthorized, "unauthorized"))
 		return
 	}
 
@@ -49,7 +53,7 @@ func (h *RoomsHandlers) Create(w http.ResponseWriter, r *http.Request) {
 	for i := 0; i < maxRetries; i++ {
 		code, err := domain.NewRoomCode(6)
 		if err != nil {
-			http.Error(w, "failed", http.StatusInternalServerError)
+			writeError(w, r, apierr.New(apierr.Internal, "failed"))
 			return
 		}
 
@@ -61,7 +65,7 @@ func (h *RoomsHandlers) Create(w http.ResponseWriter, r *http.Request) {
 
 		var pgErr *pgconn.PgError
 		if !errors.As(err, &pgErr) || pgErr.Code != "23505" {
-			http.Error(w, "failed to create room", http.StatusInternalServerError)
+			writeError(w, r, apierr.New(apierr.Internal, "failed to create room"))
 			return
 		}
 
@@ -70,13 +74,13 @@ func (h *RoomsHandlers) Create(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	http.Error(w, "could not create room after retries", http.StatusInternalServerError)
+	writeError(w, r, apierr.New(apierr.Internal, "could not create room after retries"))
 }
 
 func (h *RoomsHandlers) Join(w http.ResponseWriter, r *http.Request) {
 	userID, ok := UserIDFromContext(r.Context())
 	if !ok {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		writeError(w, r, apierr.New(apierr.Unauthorized, "unauthorized"))
 		return
 	}
 
@@ -85,22 +89,22 @@ func (h *RoomsHandlers) Join(w http.ResponseWriter, r *http.Request) {
 	bodyBytes, err := io.ReadAll(r.Body)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to read request body")
-		http.Error(w, "bad request: failed to read body", http.StatusBadRequest)
+		writeError(w, r, apierr.New(apierr.BadRequest, "bad request: failed to read body"))
 		return
 	}
 
 	if len(bodyBytes) == 0 {
-		http.Error(w, "bad request: empty body", http.StatusBadRequest)
+		writeError(w, r, apierr.New(apierr.BadRequest, "bad request: empty body"))
 		return
 	}
 
 	if err := json.Unmarshal(bodyBytes, &req); err != nil {
 		log.Error().Err(err).Bytes("body", bodyBytes).Msg("JSON decode failed")
-		http.Error(w, "bad request: invalid JSON", http.StatusBadRequest)
+		writeError(w, r, apierr.New(apierr.BadJSON, "bad request: invalid JSON"))
 		return
 	}
 	if req.Code == "" || req.DisplayName == "" {
-		http.Error(w, "code and displayName required", http.StatusBadRequest)
+		writeError(w, r, apierr.New(apierr.BadRequest, "code and displayName required"))
 		return
 	}
 
@@ -110,50 +114,41 @@ func (h *RoomsHandlers) Join(w http.ResponseWriter, r *http.Request) {
 	room, err := h.Store.GetRoomByCode(ctx, req.Code)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			http.Error(w, "room not found", http.StatusNotFound)
+			writeError(w, r, apierr.New(apierr.RoomNotFound, "room not found"))
 			return
 		}
-		http.Error(w, "failed", http.StatusInternalServerError)
+		writeError(w, r, apierr.New(apierr.Internal, "failed"))
 		return
 	}
 
 	if err := h.Store.JoinRoom(ctx, room.ID, userID, req.DisplayName); err != nil {
-		http.Error(w, "failed to join", http.StatusInternalServerError)
+		if errors.Is(err, storage.ErrBanned) || errors.Is(err, storage.ErrRoomFull) {
+			writeError(w, r, apierr.From(err))
+			return
+		}
+		writeError(w, r, apierr.New(apierr.Internal, "failed to join"))
 		return
 	}
 
+	if member, err := h.Store.GetRoomMember(ctx, room.ID, userID); err == nil {
+		h.Events.MemberJoined(ctx, room.Code, room.ID, *member)
+	}
+
 	w.WriteHeader(http.StatusNoContent)
 }
 
 func (h *RoomsHandlers) Members(w http.ResponseWriter, r *http.Request) {
-	userID, ok := UserIDFromContext(r.Context())
-	if !ok || userID == "" {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
-		return
-	}
-
-	code := r.URL.Query().Get("code")
-	if code == "" {
-		http.Error(w, "missing code", http.StatusBadRequest)
-		return
-	}
-
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
 
-	room, err := h.Store.GetRoomByCode(ctx, code)
-	if err != nil {
-		if err == pgx.ErrNoRows {
-			http.Error(w, "room not found", http.StatusNotFound)
-			return
-		}
-		http.Error(w, "failed", http.StatusInternalServerError)
+	room, ok := authorizeRoom(ctx, w, r, h.Store, r.URL.Query().Get("code"), authz.Member)
+	if !ok {
 		return
 	}
 
 	members, err := h.Store.ListRoomMembers(ctx, room.ID)
 	if err != nil {
-		http.Error(w, "failed", http.StatusInternalServerError)
+		writeError(w, r, apierr.New(apierr.Internal, "failed"))
 		return
 	}
 
@@ -161,41 +156,34 @@ func (h *RoomsHandlers) Members(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *RoomsHandlers) GetRoomStats(w http.ResponseWriter, r *http.Request) {
-	_, ok := UserIDFromContext(r.Context())
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	room, ok := authorizeRoom(ctx, w, r, h.Store, r.URL.Query().Get("code"), authz.Member)
 	if !ok {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-	code := r.URL.Query().Get("code")
-	if code == "" {
-		http.Error(w, "missing code", http.StatusBadRequest)
+	members, err := h.Store.ListRoomMembers(ctx, room.ID)
+	if err != nil {
+		writeError(w, r, apierr.New(apierr.Internal, "get members failed"))
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
-	defer cancel()
-
-	room, err := h.Store.GetRoomByCode(ctx, code)
+	packs, err := h.Store.GetRoomSelectedPackSlugs(ctx, room.ID)
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			http.Error(w, "room not found", http.StatusNotFound)
-			return
-		}
-
-		http.Error(w, "get room failed", http.StatusInternalServerError)
+		writeError(w, r, apierr.New(apierr.Internal, "get packs failed"))
 		return
 	}
 
-	members, err := h.Store.ListRoomMembers(ctx, room.ID)
+	settings, err := h.Store.GetRoomSettings(ctx, room.ID)
 	if err != nil {
-		http.Error(w, "get members failed", http.StatusInternalServerError)
+		writeError(w, r, apierr.New(apierr.Internal, "get settings failed"))
 		return
 	}
-
-	packs, err := h.Store.GetRoomSelectedPackSlugs(ctx, room.ID)
+	pool, err := h.Store.GetRoomPool(ctx, room.ID, settings.Lang, settings.TranslatedOnly)
 	if err != nil {
-		http.Error(w, "get packs failed", http.StatusInternalServerError)
+		writeError(w, r, apierr.New(apierr.Internal, "get pool failed"))
 		return
 	}
 
@@ -208,6 +196,7 @@ func (h *RoomsHandlers) GetRoomStats(w http.ResponseWriter, r *http.Request) {
 		"room":             room,
 		"members":          members,
 		"packs":            packs,
+		"pool":             pool,
 		"current_round_id": room.CurrentRoundID,
 		"round_status":     roundStatus,
 	})
@@ -6,17 +6,21 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/apierr"
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/authz"
 	"github.com/JsotoSoftware/guess-who-game-backend/internal/storage"
 	"github.com/jackc/pgx/v5"
 )
 
 type RoomPacksHandlers struct {
-	Store *storage.Storage
+	Store  *storage.Storage
+	Events RoomEvents
 }
 
-func NewRoomPacksHandlers(store *storage.Storage) *RoomPacksHandlers {
+func NewRoomPacksHandlers(store *storage.Storage, events RoomEvents) *RoomPacksHandlers {
 	return &RoomPacksHandlers{
-		Store: store,
+		Store:  store,
+		Events: events,
 	}
 }
 
@@ -27,32 +31,21 @@ type setRoomPacksReq struct {
 }
 
 func (h *RoomPacksHandlers) Set(w http.ResponseWriter, r *http.Request) {
-	_, ok := UserIDFromContext(r.Context())
-	if !ok {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
-		return
-	}
-
 	var req setRoomPacksReq
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "bad request", http.StatusBadRequest)
+		writeError(w, r, apierr.New(apierr.BadRequest, "bad request"))
 		return
 	}
 	if req.Code == "" {
-		http.Error(w, "code required", http.StatusBadRequest)
+		writeError(w, r, apierr.New(apierr.BadRequest, "code required"))
 		return
 	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
-	room, err := h.Store.GetRoomByCode(ctx, req.Code)
-	if err != nil {
-		if err == pgx.ErrNoRows {
-			http.Error(w, "room not found", http.StatusNotFound)
-			return
-		}
-		http.Error(w, "failed", http.StatusInternalServerError)
+	room, ok := authorizeRoom(ctx, w, r, h.Store, req.Code, authz.Owner)
+	if !ok {
 		return
 	}
 
@@ -60,7 +53,7 @@ func (h *RoomPacksHandlers) Set(w http.ResponseWriter, r *http.Request) {
 	if req.CollectionSlug != "" {
 		packs, err := h.Store.ListPacksForCollection(ctx, req.CollectionSlug, "es")
 		if err != nil {
-			http.Error(w, "failed", http.StatusInternalServerError)
+			writeError(w, r, apierr.New(apierr.Internal, "failed"))
 			return
 		}
 		packSlugs = packSlugs[:0]
@@ -70,43 +63,38 @@ func (h *RoomPacksHandlers) Set(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if len(packSlugs) == 0 {
-		http.Error(w, "packSlugs or collectionSlug required", http.StatusBadRequest)
+		writeError(w, r, apierr.New(apierr.BadRequest, "packSlugs or collectionSlug required"))
 		return
 	}
 
 	if err := h.Store.SetRoomPackSelectionBySlugs(ctx, room.ID, packSlugs); err != nil {
-		http.Error(w, "failed", http.StatusInternalServerError)
+		if err == pgx.ErrNoRows {
+			writeError(w, r, apierr.New(apierr.PackNotFound, "pack not found"))
+			return
+		}
+		writeError(w, r, apierr.New(apierr.Internal, "failed"))
 		return
 	}
 
+	h.Events.PacksChanged(ctx, room.Code, packSlugs)
+
 	w.WriteHeader(http.StatusNoContent)
 }
 
 func (h *RoomPacksHandlers) Get(w http.ResponseWriter, r *http.Request) {
-	_, ok := UserIDFromContext(r.Context())
-	if !ok {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
-		return
-	}
-
 	code := r.URL.Query().Get("code")
-	if code == "" {
-		http.Error(w, "code required", http.StatusBadRequest)
-		return
-	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
 
-	room, err := h.Store.GetRoomByCode(ctx, code)
-	if err != nil {
-		http.Error(w, "room not found", http.StatusNotFound)
+	room, ok := authorizeRoom(ctx, w, r, h.Store, code, authz.Member)
+	if !ok {
 		return
 	}
 
 	slugs, err := h.Store.GetRoomSelectedPackSlugs(ctx, room.ID)
 	if err != nil {
-		http.Error(w, "failed", http.StatusInternalServerError)
+		writeError(w, r, apierr.New(apierr.Internal, "failed"))
 		return
 	}
 
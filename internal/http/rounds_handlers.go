@@ -0,0 +1,124 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/apierr"
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/authz"
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/storage"
+)
+
+type RoundsHandlers struct {
+	Store *storage.Storage
+}
+
+func NewRoundsHandlers(store *storage.Storage) *RoundsHandlers {
+	return &RoundsHandlers{
+		Store: store,
+	}
+}
+
+func (h *RoundsHandlers) List(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	room, ok := authorizeRoom(ctx, w, r, h.Store, chi.URLParam(r, "code"), authz.Member)
+	if !ok {
+		return
+	}
+
+	rounds, err := h.Store.ListRounds(ctx, room.ID, limit, offset)
+	if err != nil {
+		writeError(w, r, apierr.New(apierr.Internal, "failed"))
+		return
+	}
+
+	writeJSON(w, map[string]any{"code": room.Code, "rounds": rounds})
+}
+
+func (h *RoundsHandlers) Get(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	room, ok := authorizeRoom(ctx, w, r, h.Store, chi.URLParam(r, "code"), authz.Member)
+	if !ok {
+		return
+	}
+
+	// Postgres accepts several spellings of the same UUID, so decide from the
+	// stored round rather than by comparing IDs.
+	round, err := h.Store.GetRound(ctx, chi.URLParam(r, "id"))
+	if err != nil || round.RoomID != room.ID {
+		if err == nil || err == pgx.ErrNoRows {
+			writeError(w, r, apierr.New(apierr.NotFound, "round not found"))
+			return
+		}
+		writeError(w, r, apierr.New(apierr.Internal, "failed"))
+		return
+	}
+	if round.EndedAt == nil {
+		// Revealing a round in progress would give away everyone's character.
+		writeError(w, r, apierr.New(apierr.RoundAlreadyActive, "round is still in progress"))
+		return
+	}
+
+	detail, err := h.Store.GetRoundDetail(ctx, room.ID, round.ID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			writeError(w, r, apierr.New(apierr.NotFound, "round not found"))
+			return
+		}
+		writeError(w, r, apierr.New(apierr.Internal, "failed"))
+		return
+	}
+
+	writeJSON(w, detail)
+}
+
+// Summary totals the results of the game named by ?gameId=, by default the
+// room's current or last game. Rooms that never started a game get their whole
+// history.
+func (h *RoundsHandlers) Summary(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	room, ok := authorizeRoom(ctx, w, r, h.Store, chi.URLParam(r, "code"), authz.Member)
+	if !ok {
+		return
+	}
+
+	var gameID *string
+	if v := r.URL.Query().Get("gameId"); v != "" {
+		gameID = &v
+	} else {
+		game, err := h.Store.GetLatestGame(ctx, room.ID)
+		switch {
+		case err == nil:
+			gameID = &game.ID
+		case err != pgx.ErrNoRows:
+			writeError(w, r, apierr.New(apierr.Internal, "failed"))
+			return
+		}
+	}
+
+	summary, err := h.Store.GetGameSummary(ctx, room.ID, gameID)
+	if err == pgx.ErrNoRows {
+		writeError(w, r, apierr.New(apierr.NotFound, "game not found"))
+		return
+	}
+	if err != nil {
+		writeError(w, r, apierr.New(apierr.Internal, "failed"))
+		return
+	}
+
+	writeJSON(w, map[string]any{"code": room.Code, "summary": summary})
+}
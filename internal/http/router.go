@@ -13,7 +13,7 @@ func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID")
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
 
 		if r.Method == "OPTIONS" {
@@ -47,10 +47,14 @@ func NewRouter(store *storage.Storage, tokens *auth.TokenMaker, cookieSecure boo
 	})
 
 	// Protected
-	rh := NewRoomHandlers(store)
+	rh := NewRoomHandlers(store, wsHandler)
 	ph := NewPacksHandlers(store)
 	ch := NewCollectionsHandlers(store)
-	rhp := NewRoomPacksHandlers(store)
+	rhp := NewRoomPacksHandlers(store, wsHandler)
+	sh := NewScoresHandlers(store)
+	sth := NewSettingsHandlers(store, wsHandler)
+	rdh := NewRoundsHandlers(store)
+	plh := NewPoolHandlers(store, wsHandler)
 
 	r.Route("/v1", func(r chi.Router) {
 		r.Use(RequireAuth(tokens))
@@ -61,6 +65,13 @@ func NewRouter(store *storage.Storage, tokens *auth.TokenMaker, cookieSecure boo
 		r.Get("/rooms/members", rh.Members)
 		r.Get("/rooms/packs", rhp.Get)
 		r.Get("/rooms/state", rh.GetRoomStats)
+		r.Get("/rooms/{code}/scores/history", sh.History)
+		r.Get("/rooms/{code}/settings", sth.Get)
+		r.Put("/rooms/{code}/settings", sth.Update)
+		r.Get("/rooms/{code}/rounds", rdh.List)
+		r.Get("/rooms/{code}/rounds/{id}", rdh.Get)
+		r.Get("/rooms/{code}/summary", rdh.Summary)
+		r.Get("/rooms/{code}/pool", plh.Get)
 
 		r.Get("/packs", ph.List)
 		r.Get("/packs/{slug}", ph.Get)
@@ -0,0 +1,45 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/apierr"
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/authz"
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/storage"
+)
+
+type ScoresHandlers struct {
+	Store *storage.Storage
+}
+
+func NewScoresHandlers(store *storage.Storage) *ScoresHandlers {
+	return &ScoresHandlers{
+		Store: store,
+	}
+}
+
+func (h *ScoresHandlers) History(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	room, ok := authorizeRoom(ctx, w, r, h.Store, chi.URLParam(r, "code"), authz.Member)
+	if !ok {
+		return
+	}
+
+	events, err := h.Store.ListScoreEvents(ctx, room.ID, limit, offset)
+	if err != nil {
+		writeError(w, r, apierr.New(apierr.Internal, "failed"))
+		return
+	}
+
+	writeJSON(w, map[string]any{"code": room.Code, "events": events})
+}
@@ -0,0 +1,75 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/apierr"
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/authz"
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/storage"
+)
+
+type SettingsHandlers struct {
+	Store  *storage.Storage
+	Events RoomEvents
+}
+
+func NewSettingsHandlers(store *storage.Storage, events RoomEvents) *SettingsHandlers {
+	return &SettingsHandlers{
+		Store:  store,
+		Events: events,
+	}
+}
+
+func (h *SettingsHandlers) Get(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	room, ok := authorizeRoom(ctx, w, r, h.Store, chi.URLParam(r, "code"), authz.Member)
+	if !ok {
+		return
+	}
+
+	settings, err := h.Store.GetRoomSettings(ctx, room.ID)
+	if err != nil {
+		writeError(w, r, apierr.New(apierr.Internal, "failed"))
+		return
+	}
+
+	writeJSON(w, map[string]any{"code": room.Code, "settings": settings})
+}
+
+// Update applies a partial settings document; keys left out keep their value.
+func (h *SettingsHandlers) Update(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, 64<<10))
+	if err != nil || len(body) == 0 {
+		writeError(w, r, apierr.New(apierr.BadRequest, "bad request: empty body"))
+		return
+	}
+	if !json.Valid(body) {
+		writeError(w, r, apierr.New(apierr.BadJSON, "bad request: invalid JSON"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	room, ok := authorizeRoom(ctx, w, r, h.Store, chi.URLParam(r, "code"), authz.Owner)
+	if !ok {
+		return
+	}
+
+	settings, err := h.Store.UpdateRoomSettings(ctx, room.ID, body)
+	if err != nil {
+		writeError(w, r, apierr.From(err))
+		return
+	}
+	h.Events.SettingsChanged(ctx, room.Code, room.ID, settings)
+
+	writeJSON(w, map[string]any{"code": room.Code, "settings": settings})
+}
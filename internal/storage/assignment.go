@@ -0,0 +1,282 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+)
+
+// Assignment strategy names, as stored in room settings and on room_rounds.
+const (
+	AssignUniform    = "uniform"
+	AssignBalanced   = "balanced"
+	AssignDifficulty = "difficulty"
+	AssignAvoidSeen  = "avoid_seen"
+)
+
+// Pack balance modes for AssignBalanced.
+const (
+	PackRoundRobin = "round_robin"
+	PackRandom     = "random_pack"
+)
+
+// Candidate is a character a round may draw from.
+type Candidate struct {
+	ID         string
+	Name       string
+	PackID     string
+	Difficulty int // 1 easy, 2 medium, 3 hard
+}
+
+// AssignmentInput is everything a strategy may base its draw on. Players and
+// Candidates must come in a stable order (join order and character ID) so the
+// same seed always yields the same draw.
+type AssignmentInput struct {
+	Players    []string
+	Candidates []Candidate
+
+	// Difficulty is the preferred character difficulty for AssignDifficulty.
+	Difficulty int
+
+	// PackBalance and UniquePacks shape AssignBalanced; see RoomSettings.
+	PackBalance string
+	UniquePacks bool
+
+	// Seen holds, per player, the characters they already met in other rooms.
+	// It is only loaded for strategies that ask for it.
+	Seen map[string]map[string]bool
+}
+
+// AssignmentStrategy picks one distinct candidate per player. The returned
+// slice is indexed like in.Players. All randomness must come from rng so a
+// round can be replayed from its recorded seed.
+type AssignmentStrategy interface {
+	Name() string
+	Assign(rng *rand.Rand, in AssignmentInput) ([]Candidate, error)
+}
+
+// AssignmentStrategyByName returns the strategy registered under name.
+func AssignmentStrategyByName(name string) (AssignmentStrategy, bool) {
+	switch name {
+	case AssignUniform:
+		return uniformStrategy{}, true
+	case AssignBalanced:
+		return balancedStrategy{}, true
+	case AssignDifficulty:
+		return difficultyStrategy{}, true
+	case AssignAvoidSeen:
+		return avoidSeenStrategy{}, true
+	}
+	return nil, false
+}
+
+// AssignCharacters runs strategy with the generator derived from seed. Calling
+// it again with the same seed and input reproduces the draw.
+func AssignCharacters(strategy AssignmentStrategy, seed int64, in AssignmentInput) ([]Candidate, error) {
+	if len(in.Candidates) < len(in.Players) {
+		return nil, ErrNotEnoughCharacters
+	}
+	rng := rand.New(rand.NewPCG(uint64(seed), uint64(seed)^0x9e3779b97f4a7c15))
+	return strategy.Assign(rng, in)
+}
+
+func needsSeen(strategy AssignmentStrategy) bool {
+	_, ok := strategy.(avoidSeenStrategy)
+	return ok
+}
+
+// uniformStrategy gives every candidate the same chance.
+type uniformStrategy struct{}
+
+func (uniformStrategy) Name() string { return AssignUniform }
+
+func (uniformStrategy) Assign(rng *rand.Rand, in AssignmentInput) ([]Candidate, error) {
+	pool := append([]Candidate(nil), in.Candidates...)
+	rng.Shuffle(len(pool), func(i, j int) { pool[i], pool[j] = pool[j], pool[i] })
+	return pool[:len(in.Players)], nil
+}
+
+// balancedStrategy spreads a round over the selected packs instead of over
+// their characters, so a small pack shows up as often as a large one while it
+// lasts. In round-robin mode packs take turns; in random-pack mode each player
+// draws a random pack that still has characters, giving every selected pack the
+// same share on average.
+type balancedStrategy struct{}
+
+func (balancedStrategy) Name() string { return AssignBalanced }
+
+func (balancedStrategy) Assign(rng *rand.Rand, in AssignmentInput) ([]Candidate, error) {
+	packs := groupByPack(in.Candidates)
+	for _, p := range packs {
+		rng.Shuffle(len(p), func(i, j int) { p[i], p[j] = p[j], p[i] })
+	}
+	rng.Shuffle(len(packs), func(i, j int) { packs[i], packs[j] = packs[j], packs[i] })
+
+	need := len(in.Players)
+	if in.UniquePacks && len(packs) < need {
+		return nil, fmt.Errorf("%w: %d players but only %d packs have characters left", ErrNotEnoughCharacters, need, len(packs))
+	}
+
+	out := make([]Candidate, 0, need)
+	switch {
+	case in.UniquePacks:
+		// The pack order is already random, so both modes take one
+		// character from each of the first packs.
+		for _, p := range packs[:need] {
+			out = append(out, p[0])
+		}
+
+	case in.PackBalance == PackRandom:
+		for len(out) < need {
+			open := packs[:0]
+			for _, p := range packs {
+				if len(p) > 0 {
+					open = append(open, p)
+				}
+			}
+			packs = open
+			i := rng.IntN(len(packs))
+			out = append(out, packs[i][0])
+			packs[i] = packs[i][1:]
+		}
+
+	default:
+		for len(out) < need {
+			for i := range packs {
+				if len(packs[i]) == 0 || len(out) == need {
+					continue
+				}
+				out = append(out, packs[i][0])
+				packs[i] = packs[i][1:]
+			}
+		}
+		// Dealing in pack order would give the first players the first pack.
+		rng.Shuffle(len(out), func(i, j int) { out[i], out[j] = out[j], out[i] })
+	}
+	return out, nil
+}
+
+// difficultyStrategy favours characters close to the preferred difficulty
+// without ruling the others out. Characters without a difficulty count as
+// medium.
+type difficultyStrategy struct{}
+
+func (difficultyStrategy) Name() string { return AssignDifficulty }
+
+func (difficultyStrategy) Assign(rng *rand.Rand, in AssignmentInput) ([]Candidate, error) {
+	target := in.Difficulty
+	if target == 0 {
+		target = 2
+	}
+
+	// Weighted sampling without replacement (Efraimidis-Spirakis): keep the
+	// candidates with the largest u^(1/w).
+	type keyed struct {
+		c   Candidate
+		key float64
+	}
+	keys := make([]keyed, len(in.Candidates))
+	for i, c := range in.Candidates {
+		d := c.Difficulty
+		if d == 0 {
+			d = 2
+		}
+		dist := d - target
+		if dist < 0 {
+			dist = -dist
+		}
+		w := 4 / math.Pow(2, float64(dist)) // 4, 2, 1
+		keys[i] = keyed{c: c, key: math.Pow(rng.Float64(), 1/w)}
+	}
+	sort.SliceStable(keys, func(i, j int) bool { return keys[i].key > keys[j].key })
+
+	out := make([]Candidate, len(in.Players))
+	for i := range out {
+		out[i] = keys[i].c
+	}
+	return out, nil
+}
+
+// avoidSeenStrategy gives each player, in turn, a character they have not met
+// in another room, falling back to any remaining character once they have
+// seen them all.
+type avoidSeenStrategy struct{}
+
+func (avoidSeenStrategy) Name() string { return AssignAvoidSeen }
+
+func (avoidSeenStrategy) Assign(rng *rand.Rand, in AssignmentInput) ([]Candidate, error) {
+	pool := append([]Candidate(nil), in.Candidates...)
+	rng.Shuffle(len(pool), func(i, j int) { pool[i], pool[j] = pool[j], pool[i] })
+
+	out := make([]Candidate, len(in.Players))
+	for i, uid := range in.Players {
+		seen := in.Seen[uid]
+		pick := 0
+		for j, c := range pool {
+			if !seen[c.ID] {
+				pick = j
+				break
+			}
+		}
+		out[i] = pool[pick]
+		pool = append(pool[:pick], pool[pick+1:]...)
+	}
+	return out, nil
+}
+
+// groupByPack splits candidates by pack, keeping the packs in order of first
+// appearance so the grouping is deterministic.
+func groupByPack(candidates []Candidate) [][]Candidate {
+	idx := map[string]int{}
+	var packs [][]Candidate
+	for _, c := range candidates {
+		i, ok := idx[c.PackID]
+		if !ok {
+			i = len(packs)
+			idx[c.PackID] = i
+			packs = append(packs, nil)
+		}
+		packs[i] = append(packs[i], c)
+	}
+	return packs
+}
+
+// assignmentParams is the part of AssignmentInput stored as JSON on room_rounds.
+type assignmentParams struct {
+	Difficulty  int                 `json:"difficulty"`
+	PackBalance string              `json:"packBalance,omitempty"`
+	UniquePacks bool                `json:"uniquePacks,omitempty"`
+	Seen        map[string][]string `json:"seen,omitempty"` // only characters that were candidates
+}
+
+// recordAssignmentInput flattens in for storage on room_rounds.
+func recordAssignmentInput(in AssignmentInput) (players, candidates []string, params []byte, err error) {
+	candidates = make([]string, len(in.Candidates))
+	isCandidate := make(map[string]bool, len(in.Candidates))
+	for i, c := range in.Candidates {
+		candidates[i] = c.ID
+		isCandidate[c.ID] = true
+	}
+
+	p := assignmentParams{Difficulty: in.Difficulty, PackBalance: in.PackBalance, UniquePacks: in.UniquePacks}
+	for uid, seen := range in.Seen {
+		var ids []string
+		for id := range seen {
+			if isCandidate[id] {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) > 0 {
+			if p.Seen == nil {
+				p.Seen = map[string][]string{}
+			}
+			sort.Strings(ids)
+			p.Seen[uid] = ids
+		}
+	}
+
+	params, err = json.Marshal(p)
+	return in.Players, candidates, params, err
+}
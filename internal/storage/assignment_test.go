@@ -0,0 +1,180 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// testInput returns four players and twelve candidates spread over three packs
+// of unequal size, with every difficulty represented.
+func testInput() AssignmentInput {
+	in := AssignmentInput{Players: []string{"p1", "p2", "p3", "p4"}}
+	sizes := map[string]int{"pack-a": 8, "pack-b": 3, "pack-c": 1}
+	for _, pack := range []string{"pack-a", "pack-b", "pack-c"} {
+		for i := 0; i < sizes[pack]; i++ {
+			id := fmt.Sprintf("%s-%d", pack, i)
+			in.Candidates = append(in.Candidates, Candidate{ID: id, Name: id, PackID: pack, Difficulty: i%3 + 1})
+		}
+	}
+	return in
+}
+
+type strategyCase struct {
+	name     string
+	strategy string
+	in       AssignmentInput
+}
+
+func strategyCases() []strategyCase {
+	randomPack := testInput()
+	randomPack.PackBalance = PackRandom
+	unique := testInput()
+	unique.Players = unique.Players[:3]
+	unique.UniquePacks = true
+	difficulty := testInput()
+	difficulty.Difficulty = 3
+	avoid := testInput()
+	avoid.Seen = map[string]map[string]bool{"p1": {"pack-a-0": true, "pack-a-1": true}}
+
+	return []strategyCase{
+		{"uniform", AssignUniform, testInput()},
+		{"balanced round robin", AssignBalanced, testInput()},
+		{"balanced random pack", AssignBalanced, randomPack},
+		{"balanced unique packs", AssignBalanced, unique},
+		{"difficulty", AssignDifficulty, difficulty},
+		{"avoid seen", AssignAvoidSeen, avoid},
+	}
+}
+
+func TestAssignCharactersIsSeeded(t *testing.T) {
+	for _, tc := range strategyCases() {
+		t.Run(tc.name, func(t *testing.T) {
+			in := tc.in
+			strategy, ok := AssignmentStrategyByName(tc.strategy)
+			if !ok {
+				t.Fatalf("strategy %q not registered", tc.strategy)
+			}
+
+			first, err := AssignCharacters(strategy, 42, in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(first) != len(in.Players) {
+				t.Fatalf("got %d characters for %d players", len(first), len(in.Players))
+			}
+			picked := map[string]bool{}
+			for _, c := range first {
+				if picked[c.ID] {
+					t.Fatalf("character %s assigned twice: %v", c.ID, first)
+				}
+				picked[c.ID] = true
+			}
+
+			for i := 0; i < 5; i++ {
+				again, err := AssignCharacters(strategy, 42, in)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(first, again) {
+					t.Fatalf("same seed drew %v, then %v", first, again)
+				}
+			}
+
+			differs := false
+			for seed := int64(1); seed <= 20 && !differs; seed++ {
+				other, err := AssignCharacters(strategy, seed, in)
+				if err != nil {
+					t.Fatal(err)
+				}
+				differs = !reflect.DeepEqual(first, other)
+			}
+			if !differs {
+				t.Fatal("twenty different seeds all drew the same characters")
+			}
+		})
+	}
+}
+
+func TestAssignCharactersNotEnough(t *testing.T) {
+	in := testInput()
+	in.Candidates = in.Candidates[:3]
+	if _, err := AssignCharacters(uniformStrategy{}, 1, in); !errors.Is(err, ErrNotEnoughCharacters) {
+		t.Fatalf("err = %v, want ErrNotEnoughCharacters", err)
+	}
+}
+
+func TestBalancedRoundRobinSpreadsPacks(t *testing.T) {
+	in := testInput()
+	in.Players = in.Players[:3]
+	for seed := int64(0); seed < 50; seed++ {
+		out, err := AssignCharacters(balancedStrategy{}, seed, in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		packs := map[string]bool{}
+		for _, c := range out {
+			packs[c.PackID] = true
+		}
+		if len(packs) != 3 {
+			t.Fatalf("seed %d: three players drew from %d packs: %v", seed, len(packs), out)
+		}
+	}
+}
+
+func TestBalancedUniquePacks(t *testing.T) {
+	in := testInput()
+	in.UniquePacks = true
+	if _, err := AssignCharacters(balancedStrategy{}, 1, in); !errors.Is(err, ErrNotEnoughCharacters) {
+		t.Fatalf("four players over three packs: err = %v, want ErrNotEnoughCharacters", err)
+	}
+}
+
+func TestAvoidSeenPrefersUnseen(t *testing.T) {
+	in := testInput()
+	in.Players = []string{"p1"}
+	in.Seen = map[string]map[string]bool{"p1": {}}
+	for _, c := range in.Candidates[1:] {
+		in.Seen["p1"][c.ID] = true
+	}
+	for seed := int64(0); seed < 20; seed++ {
+		out, err := AssignCharacters(avoidSeenStrategy{}, seed, in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out[0].ID != in.Candidates[0].ID {
+			t.Fatalf("seed %d: drew seen character %s", seed, out[0].ID)
+		}
+	}
+}
+
+func TestRecordAssignmentInput(t *testing.T) {
+	in := testInput()
+	in.Difficulty = 1
+	in.Seen = map[string]map[string]bool{
+		"p1": {"pack-b-0": true, "pack-a-2": true, "retired": true},
+		"p2": {"retired": true},
+	}
+
+	players, candidates, raw, err := recordAssignmentInput(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(players, in.Players) {
+		t.Fatalf("players = %v, want %v", players, in.Players)
+	}
+	if len(candidates) != len(in.Candidates) || candidates[0] != "pack-a-0" {
+		t.Fatalf("candidates = %v, want the candidate IDs in input order", candidates)
+	}
+
+	var params assignmentParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		t.Fatal(err)
+	}
+	want := assignmentParams{Difficulty: 1, Seen: map[string][]string{"p1": {"pack-a-2", "pack-b-0"}}}
+	if !reflect.DeepEqual(params, want) {
+		t.Fatalf("params = %+v, want %+v", params, want)
+	}
+}
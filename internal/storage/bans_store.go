@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var ErrBanned = errors.New("user is banned from this room")
+
+// IsBanned reports whether userID is banned from the room.
+func (s *Storage) IsBanned(ctx context.Context, roomID, userID string) (bool, error) {
+	var banned bool
+	err := s.PG.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM room_bans WHERE room_id = $1 AND user_id = $2)
+	`, roomID, userID).Scan(&banned)
+	return banned, err
+}
+
+// KickRoomMember removes userID from the room. It returns pgx.ErrNoRows if the
+// user is not a member. A kicked user may join again; see BanRoomMember.
+func (s *Storage) KickRoomMember(ctx context.Context, roomID, userID string) error {
+	tag, err := s.PG.Exec(ctx, `
+		DELETE FROM room_members WHERE room_id = $1 AND user_id = $2
+	`, roomID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// BanRoomMember records a ban and removes userID from the room if present. It
+// returns pgx.ErrNoRows if userID is not a known user.
+func (s *Storage) BanRoomMember(ctx context.Context, roomID, userID, bannedBy, reason string) (err error) {
+	tx, err := s.PG.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO room_bans (room_id, user_id, banned_by, reason)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (room_id, user_id) DO UPDATE
+		SET banned_by = EXCLUDED.banned_by,
+		    reason = EXCLUDED.reason
+	`, roomID, userID, bannedBy, reason)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			err = pgx.ErrNoRows
+		}
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM room_members WHERE room_id = $1 AND user_id = $2`, roomID, userID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// UnbanRoomMember lifts a ban. It returns pgx.ErrNoRows if userID was not banned.
+func (s *Storage) UnbanRoomMember(ctx context.Context, roomID, userID string) error {
+	tag, err := s.PG.Exec(ctx, `DELETE FROM room_bans WHERE room_id = $1 AND user_id = $2`, roomID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,387 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrNoActiveRound      = errors.New("no active round")
+	ErrNotInRound         = errors.New("user has no assignment in the current round")
+	ErrAlreadyGuessed     = errors.New("user already guessed their character this round")
+	ErrClaimAlreadyOpen   = errors.New("another claim is already open in this room")
+	ErrClaimNotOpen       = errors.New("claim is not open")
+	ErrCannotVoteOwnClaim = errors.New("cannot vote on your own claim")
+	ErrSpectator          = errors.New("spectators cannot take part in the round")
+	ErrNotMember          = errors.New("user is not a member of this room")
+)
+
+const (
+	ClaimOpen     = "open"
+	ClaimApproved = "approved"
+	ClaimRejected = "rejected"
+	ClaimTimedOut = "timed_out"
+
+	ResolvedByVotes   = "votes"
+	ResolvedByTimeout = "timeout"
+
+	VoteYes = "yes"
+	VoteNo  = "no"
+)
+
+type Claim struct {
+	ID             string     `json:"id"`
+	RoomID         string     `json:"roomId"`
+	RoundID        string     `json:"roundId"`
+	ClaimantUserID string     `json:"claimantUserId"`
+	Status         string     `json:"status"`
+	OpenedAt       time.Time  `json:"openedAt"`
+	EndsAt         time.Time  `json:"endsAt"`
+	ResolvedAt     *time.Time `json:"resolvedAt,omitempty"`
+	ResolvedBy     *string    `json:"resolvedBy,omitempty"`
+}
+
+type ClaimTally struct {
+	Yes int `json:"yes"`
+	No  int `json:"no"`
+}
+
+const claimColumns = `id, room_id, round_id, claimant_user_id, status, opened_at, ends_at, resolved_at, resolved_by`
+
+func scanClaim(row pgx.Row) (*Claim, error) {
+	var c Claim
+	if err := row.Scan(
+		&c.ID, &c.RoomID, &c.RoundID, &c.ClaimantUserID, &c.Status,
+		&c.OpenedAt, &c.EndsAt, &c.ResolvedAt, &c.ResolvedBy,
+	); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// DecideClaim returns the outcome of a claim once the votes cast so far settle it.
+// A claim is approved by a strict majority of eligible voters and rejected as soon
+// as a majority is no longer reachable.
+func DecideClaim(t ClaimTally, eligibleVoters int) (status string, decided bool) {
+	if eligibleVoters <= 0 {
+		return "", false
+	}
+	if t.Yes*2 > eligibleVoters {
+		return ClaimApproved, true
+	}
+	if t.No*2 >= eligibleVoters {
+		return ClaimRejected, true
+	}
+	return "", false
+}
+
+func (s *Storage) OpenClaim(ctx context.Context, roomID, claimantUserID string, ttl time.Duration) (claim *Claim, err error) {
+	tx, err := s.PG.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	var cur *string
+	if err = tx.QueryRow(ctx, `SELECT current_round_id FROM rooms WHERE id=$1 FOR UPDATE`, roomID).Scan(&cur); err != nil {
+		return nil, err
+	}
+	if cur == nil || *cur == "" {
+		return nil, ErrNoActiveRound
+	}
+
+	var assigned, guessed, open bool
+	if err = tx.QueryRow(ctx, `
+		SELECT
+			EXISTS (SELECT 1 FROM round_assignments WHERE round_id=$1 AND user_id=$2),
+			EXISTS (SELECT 1 FROM round_claims WHERE round_id=$1 AND claimant_user_id=$2 AND status='approved'),
+			EXISTS (SELECT 1 FROM round_claims WHERE room_id=$3 AND status='open')
+	`, *cur, claimantUserID, roomID).Scan(&assigned, &guessed, &open); err != nil {
+		return nil, err
+	}
+	if !assigned {
+		return nil, ErrNotInRound
+	}
+	if guessed {
+		return nil, ErrAlreadyGuessed
+	}
+	if open {
+		return nil, ErrClaimAlreadyOpen
+	}
+
+	claim, err = scanClaim(tx.QueryRow(ctx, `
+		INSERT INTO round_claims (room_id, round_id, claimant_user_id, status, opened_at, ends_at)
+		VALUES ($1, $2, $3, 'open', now(), now() + $4 * interval '1 millisecond')
+		RETURNING `+claimColumns,
+		roomID, *cur, claimantUserID, ttl.Milliseconds()))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			err = ErrClaimAlreadyOpen
+		}
+		return nil, err
+	}
+
+	_, _ = tx.Exec(ctx, `UPDATE rooms SET last_activity_at=now() WHERE id=$1`, roomID)
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return claim, nil
+}
+
+// CastClaimVote records voterUserID's vote on an open claim of the room. It
+// returns pgx.ErrNoRows for unknown or malformed claim IDs and ErrNotMember if
+// the voter is not in the room.
+func (s *Storage) CastClaimVote(ctx context.Context, roomID, claimID, voterUserID, vote string) (claim *Claim, tally ClaimTally, err error) {
+	tx, err := s.PG.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, ClaimTally{}, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	claim, err = scanClaim(tx.QueryRow(ctx, `
+		SELECT `+claimColumns+`
+		FROM round_claims
+		WHERE id=$1 AND room_id=$2
+		FOR UPDATE
+	`, claimID, roomID))
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "22P02" {
+		err = pgx.ErrNoRows
+	}
+	if err != nil {
+		return nil, ClaimTally{}, err
+	}
+	if claim.Status != ClaimOpen || !time.Now().Before(claim.EndsAt) {
+		return nil, ClaimTally{}, ErrClaimNotOpen
+	}
+	if claim.ClaimantUserID == voterUserID {
+		return nil, ClaimTally{}, ErrCannotVoteOwnClaim
+	}
+
+	var role string
+	if err = tx.QueryRow(ctx, `
+		SELECT role FROM room_members WHERE room_id=$1 AND user_id=$2
+	`, roomID, voterUserID).Scan(&role); err != nil {
+		if err == pgx.ErrNoRows {
+			err = ErrNotMember
+		}
+		return nil, ClaimTally{}, err
+	}
+	if role == "spectator" {
+		return nil, ClaimTally{}, ErrSpectator
+	}
+
+	if _, err = tx.Exec(ctx, `
+		INSERT INTO round_claim_votes (claim_id, voter_user_id, vote, voted_at)
+		VALUES ($1, $2, $3, now())
+		ON CONFLICT (claim_id, voter_user_id) DO UPDATE
+		SET vote = EXCLUDED.vote, voted_at = EXCLUDED.voted_at
+	`, claimID, voterUserID, vote); err != nil {
+		return nil, ClaimTally{}, err
+	}
+
+	if tally, err = claimTally(ctx, tx, claimID); err != nil {
+		return nil, ClaimTally{}, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, ClaimTally{}, err
+	}
+	return claim, tally, nil
+}
+
+// ResolveClaim moves an open claim to its final status and applies the room's
+// scoring policy in the same transaction. It returns ErrClaimNotOpen when the
+// claim was already resolved by someone else.
+func (s *Storage) ResolveClaim(ctx context.Context, claimID, status, resolvedBy string) (claim *Claim, tally ClaimTally, deltas []ScoreDelta, err error) {
+	tx, err := s.PG.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, ClaimTally{}, nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	claim, err = scanClaim(tx.QueryRow(ctx, `
+		UPDATE round_claims
+		SET status=$2, resolved_at=now(), resolved_by=$3
+		WHERE id=$1 AND status='open'
+		RETURNING `+claimColumns,
+		claimID, status, resolvedBy))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			err = ErrClaimNotOpen
+		}
+		return nil, ClaimTally{}, nil, err
+	}
+
+	if tally, err = claimTally(ctx, tx, claimID); err != nil {
+		return nil, ClaimTally{}, nil, err
+	}
+	if deltas, err = applyClaimScoring(ctx, tx, claim); err != nil {
+		return nil, ClaimTally{}, nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, ClaimTally{}, nil, err
+	}
+	return claim, tally, deltas, nil
+}
+
+type queryRower interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func claimTally(ctx context.Context, q queryRower, claimID string) (ClaimTally, error) {
+	var t ClaimTally
+	err := q.QueryRow(ctx, `
+		SELECT
+			COUNT(*) FILTER (WHERE vote='yes'),
+			COUNT(*) FILTER (WHERE vote='no')
+		FROM round_claim_votes
+		WHERE claim_id=$1
+	`, claimID).Scan(&t.Yes, &t.No)
+	return t, err
+}
+
+// TimeoutOutcome decides an expired claim from the votes cast before its deadline.
+func TimeoutOutcome(t ClaimTally) string {
+	switch {
+	case t.Yes > t.No:
+		return ClaimApproved
+	case t.No > t.Yes:
+		return ClaimRejected
+	default:
+		return ClaimTimedOut
+	}
+}
+
+// ExpiredClaim is a claim decided by its votes because its deadline passed or
+// its round ended. RoomCode is only set by ResolveExpiredClaims.
+type ExpiredClaim struct {
+	Claim    *Claim
+	Tally    ClaimTally
+	Deltas   []ScoreDelta
+	RoomCode string
+}
+
+// ResolveExpiredClaims resolves up to limit open claims whose deadline has passed.
+// Rows are claimed with SKIP LOCKED so several server instances can run it concurrently.
+func (s *Storage) ResolveExpiredClaims(ctx context.Context, limit int) (out []ExpiredClaim, err error) {
+	tx, err := s.PG.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	rows, err := tx.Query(ctx, `
+		SELECT rc.id, r.code
+		FROM round_claims rc
+		JOIN rooms r ON r.id = rc.room_id
+		WHERE rc.status = 'open' AND rc.ends_at <= now()
+		ORDER BY rc.ends_at ASC
+		LIMIT $1
+		FOR UPDATE OF rc SKIP LOCKED
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	type expired struct {
+		id   string
+		code string
+	}
+	var due []expired
+	for rows.Next() {
+		var e expired
+		if err = rows.Scan(&e.id, &e.code); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		due = append(due, e)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, e := range due {
+		var ec ExpiredClaim
+		if ec, err = timeOutClaim(ctx, tx, e.id); err != nil {
+			return nil, err
+		}
+		ec.RoomCode = e.code
+		out = append(out, ec)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// timeOutClaim decides an open claim from the votes cast so far and applies the
+// room's scoring policy. The caller must hold the claim row lock.
+func timeOutClaim(ctx context.Context, tx pgx.Tx, claimID string) (ExpiredClaim, error) {
+	tally, err := claimTally(ctx, tx, claimID)
+	if err != nil {
+		return ExpiredClaim{}, err
+	}
+
+	claim, err := scanClaim(tx.QueryRow(ctx, `
+		UPDATE round_claims
+		SET status=$2, resolved_at=now(), resolved_by=$3
+		WHERE id=$1
+		RETURNING `+claimColumns,
+		claimID, TimeoutOutcome(tally), ResolvedByTimeout))
+	if err != nil {
+		return ExpiredClaim{}, err
+	}
+
+	deltas, err := applyClaimScoring(ctx, tx, claim)
+	if err != nil {
+		return ExpiredClaim{}, err
+	}
+	return ExpiredClaim{Claim: claim, Tally: tally, Deltas: deltas}, nil
+}
+
+// GetOpenClaim returns the room's open claim, or nil when there is none.
+func (s *Storage) GetOpenClaim(ctx context.Context, roomID string) (*Claim, ClaimTally, error) {
+	claim, err := scanClaim(s.PG.QueryRow(ctx, `
+		SELECT `+claimColumns+`
+		FROM round_claims
+		WHERE room_id=$1 AND status='open'
+	`, roomID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ClaimTally{}, nil
+		}
+		return nil, ClaimTally{}, err
+	}
+
+	tally, err := claimTally(ctx, s.PG, claim.ID)
+	if err != nil {
+		return nil, ClaimTally{}, err
+	}
+	return claim, tally, nil
+}
@@ -0,0 +1,275 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrGameAlreadyActive = errors.New("current game must be ended before starting a new one")
+	ErrNoActiveGame      = errors.New("no active game")
+)
+
+type Game struct {
+	ID           string     `json:"id"`
+	RoomID       string     `json:"roomId"`
+	Status       string     `json:"status"`
+	TargetRounds *int       `json:"targetRounds,omitempty"`
+	TargetScore  *int       `json:"targetScore,omitempty"`
+	StartedBy    *string    `json:"startedBy,omitempty"`
+	StartedAt    time.Time  `json:"startedAt"`
+	EndedAt      *time.Time `json:"endedAt,omitempty"`
+	RoundsPlayed int        `json:"roundsPlayed"`
+}
+
+const gameColumns = `g.id, g.room_id, g.status, g.target_rounds, g.target_score, g.started_by, g.started_at, g.ended_at,
+	(SELECT COUNT(*) FROM room_rounds rr WHERE rr.game_id = g.id AND rr.ended_at IS NOT NULL)`
+
+func scanGame(row pgx.Row) (*Game, error) {
+	var g Game
+	if err := row.Scan(
+		&g.ID, &g.RoomID, &g.Status, &g.TargetRounds, &g.TargetScore,
+		&g.StartedBy, &g.StartedAt, &g.EndedAt, &g.RoundsPlayed,
+	); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+type PodiumEntry struct {
+	Rank        int    `json:"rank"`
+	UserID      string `json:"userId"`
+	DisplayName string `json:"displayName"`
+	Score       int    `json:"score"`
+}
+
+// StartGame opens a new game in the room and resets every member's score so the
+// game starts from zero. targetRounds and targetScore are optional; without
+// either the game lasts until the host ends it.
+func (s *Storage) StartGame(ctx context.Context, roomID, startedBy string, targetRounds, targetScore *int) (g *Game, err error) {
+	tx, err := s.PG.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	var cur *string
+	if err = tx.QueryRow(ctx, `SELECT current_round_id FROM rooms WHERE id=$1 FOR UPDATE`, roomID).Scan(&cur); err != nil {
+		return nil, err
+	}
+	if cur != nil && *cur != "" {
+		return nil, ErrRoundAlreadyActive
+	}
+
+	var active bool
+	if err = tx.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM games WHERE room_id=$1 AND status='active')
+	`, roomID).Scan(&active); err != nil {
+		return nil, err
+	}
+	if active {
+		return nil, ErrGameAlreadyActive
+	}
+
+	// Reset before the game exists so the reset entries close the previous
+	// scores instead of counting against the new game.
+	if err = resetScores(ctx, tx, roomID, &startedBy); err != nil {
+		return nil, err
+	}
+
+	var gameID string
+	if err = tx.QueryRow(ctx, `
+		INSERT INTO games (room_id, target_rounds, target_score, started_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, roomID, targetRounds, targetScore, startedBy).Scan(&gameID); err != nil {
+		return nil, err
+	}
+	_, _ = tx.Exec(ctx, `UPDATE rooms SET last_activity_at=now() WHERE id=$1`, roomID)
+
+	if g, err = scanGame(tx.QueryRow(ctx, `SELECT `+gameColumns+` FROM games g WHERE g.id=$1`, gameID)); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// GetActiveGame returns the room's game in progress, or pgx.ErrNoRows.
+func (s *Storage) GetActiveGame(ctx context.Context, roomID string) (*Game, error) {
+	return scanGame(s.PG.QueryRow(ctx, `
+		SELECT `+gameColumns+`
+		FROM games g
+		WHERE g.room_id=$1 AND g.status='active'
+	`, roomID))
+}
+
+// GetLatestGame returns the room's active game, or the last one it played.
+func (s *Storage) GetLatestGame(ctx context.Context, roomID string) (*Game, error) {
+	return scanGame(s.PG.QueryRow(ctx, `
+		SELECT `+gameColumns+`
+		FROM games g
+		WHERE g.room_id=$1
+		ORDER BY g.started_at DESC
+		LIMIT 1
+	`, roomID))
+}
+
+// EndGame ends the room's active game. A round still in progress is ended with
+// it so its results, including its open claims, count towards the game.
+func (s *Storage) EndGame(ctx context.Context, roomID string) (g *Game, endedRoundID string, claims []ExpiredClaim, err error) {
+	tx, err := s.PG.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, "", nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	var cur *string
+	if err = tx.QueryRow(ctx, `SELECT current_round_id FROM rooms WHERE id=$1 FOR UPDATE`, roomID).Scan(&cur); err != nil {
+		return nil, "", nil, err
+	}
+
+	var gameID string
+	err = tx.QueryRow(ctx, `SELECT id FROM games WHERE room_id=$1 AND status='active'`, roomID).Scan(&gameID)
+	if err == pgx.ErrNoRows {
+		err = ErrNoActiveGame
+	}
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	if cur != nil && *cur != "" {
+		if claims, err = endRound(ctx, tx, roomID, *cur); err != nil {
+			return nil, "", nil, err
+		}
+		endedRoundID = *cur
+	}
+
+	if g, err = endGame(ctx, tx, gameID); err != nil {
+		return nil, "", nil, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return nil, "", nil, err
+	}
+	return g, endedRoundID, claims, nil
+}
+
+// CompleteGameIfDone ends the room's active game once it has reached its target
+// number of rounds or a player has reached its target score. It returns nil when
+// there is no active game or the game goes on.
+func (s *Storage) CompleteGameIfDone(ctx context.Context, roomID string) (g *Game, err error) {
+	tx, err := s.PG.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	if _, err = tx.Exec(ctx, `SELECT 1 FROM rooms WHERE id=$1 FOR UPDATE`, roomID); err != nil {
+		return nil, err
+	}
+
+	active, err := scanGame(tx.QueryRow(ctx, `
+		SELECT `+gameColumns+`
+		FROM games g
+		WHERE g.room_id=$1 AND g.status='active'
+	`, roomID))
+	if err == pgx.ErrNoRows {
+		return nil, tx.Commit(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	done := active.TargetRounds != nil && active.RoundsPlayed >= *active.TargetRounds
+	if !done && active.TargetScore != nil {
+		if err = tx.QueryRow(ctx, `
+			SELECT EXISTS (
+				SELECT 1 FROM score_events
+				WHERE game_id=$1
+				GROUP BY user_id
+				HAVING SUM(delta) >= $2
+			)
+		`, active.ID, *active.TargetScore).Scan(&done); err != nil {
+			return nil, err
+		}
+	}
+	if !done {
+		return nil, tx.Commit(ctx)
+	}
+
+	if g, err = endGame(ctx, tx, active.ID); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func endGame(ctx context.Context, tx pgx.Tx, gameID string) (*Game, error) {
+	return scanGame(tx.QueryRow(ctx, `
+		UPDATE games g
+		SET status='ended', ended_at=now()
+		WHERE g.id=$1
+		RETURNING `+gameColumns,
+		gameID))
+}
+
+// GamePodium ranks the game's players by the points they earned in it. Ties
+// share a rank. Players are the non-spectator members who got a character or a
+// score change during the game.
+func (s *Storage) GamePodium(ctx context.Context, gameID string) ([]PodiumEntry, error) {
+	rows, err := s.PG.Query(ctx, `
+		SELECT
+			RANK() OVER (ORDER BY COALESCE(se.total, 0) DESC)::int,
+			m.user_id,
+			m.display_name,
+			COALESCE(se.total, 0)::int
+		FROM games g
+		JOIN room_members m ON m.room_id = g.room_id
+		LEFT JOIN (
+			SELECT user_id, SUM(delta) AS total
+			FROM score_events
+			WHERE game_id = $1
+			GROUP BY user_id
+		) se ON se.user_id = m.user_id
+		WHERE g.id = $1
+		  AND m.role <> 'spectator'
+		  AND (se.user_id IS NOT NULL OR EXISTS (
+			SELECT 1 FROM round_assignments ra
+			JOIN room_rounds rr ON rr.id = ra.round_id
+			WHERE rr.game_id = g.id AND ra.user_id = m.user_id
+		  ))
+		ORDER BY 1 ASC, m.joined_at ASC
+	`, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []PodiumEntry{}
+	for rows.Next() {
+		var p PodiumEntry
+		if err := rows.Scan(&p.Rank, &p.UserID, &p.DisplayName, &p.Score); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,224 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type RoundListItem struct {
+	Round
+	PlayerCount int `json:"playerCount"`
+}
+
+// ListRounds returns the room's rounds, newest first.
+func (s *Storage) ListRounds(ctx context.Context, roomID string, limit, offset int) ([]RoundListItem, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	rows, err := s.PG.Query(ctx, `
+		SELECT rr.id, rr.room_id, rr.game_id, rr.lang, rr.started_at, rr.ends_at, rr.ended_at,
+		       (SELECT COUNT(*) FROM round_assignments ra WHERE ra.round_id = rr.id)
+		FROM room_rounds rr
+		WHERE rr.room_id = $1
+		ORDER BY rr.started_at DESC
+		LIMIT $2 OFFSET $3
+	`, roomID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []RoundListItem{}
+	for rows.Next() {
+		var it RoundListItem
+		if err := rows.Scan(&it.ID, &it.RoomID, &it.GameID, &it.Lang, &it.StartedAt, &it.EndsAt, &it.EndedAt, &it.PlayerCount); err != nil {
+			return nil, err
+		}
+		out = append(out, it)
+	}
+	return out, rows.Err()
+}
+
+type ClaimVote struct {
+	VoterUserID string    `json:"voterUserId"`
+	Vote        string    `json:"vote"`
+	VotedAt     time.Time `json:"votedAt"`
+}
+
+type ClaimRecord struct {
+	Claim
+	Votes []ClaimVote `json:"votes"`
+}
+
+type RoundDetail struct {
+	RoundSummary
+	Claims      []ClaimRecord `json:"claims"`
+	ScoreEvents []ScoreEvent  `json:"scoreEvents"`
+}
+
+// GetRoundDetail returns everything recorded about a round of the room. It
+// returns pgx.ErrNoRows if the round does not belong to the room.
+func (s *Storage) GetRoundDetail(ctx context.Context, roomID, roundID string) (*RoundDetail, error) {
+	summary, err := s.GetRoundSummary(ctx, roundID)
+	if err != nil {
+		return nil, err
+	}
+	if summary.Round.RoomID != roomID {
+		return nil, pgx.ErrNoRows
+	}
+	d := &RoundDetail{RoundSummary: *summary, Claims: []ClaimRecord{}, ScoreEvents: []ScoreEvent{}}
+
+	rows, err := s.PG.Query(ctx, `
+		SELECT `+claimColumns+`
+		FROM round_claims
+		WHERE round_id = $1
+		ORDER BY opened_at ASC
+	`, roundID)
+	if err != nil {
+		return nil, err
+	}
+	byID := map[string]int{}
+	for rows.Next() {
+		c, err := scanClaim(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		byID[c.ID] = len(d.Claims)
+		d.Claims = append(d.Claims, ClaimRecord{Claim: *c, Votes: []ClaimVote{}})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = s.PG.Query(ctx, `
+		SELECT v.claim_id, v.voter_user_id, v.vote, v.voted_at
+		FROM round_claim_votes v
+		JOIN round_claims rc ON rc.id = v.claim_id
+		WHERE rc.round_id = $1
+		ORDER BY v.voted_at ASC
+	`, roundID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var claimID string
+		var v ClaimVote
+		if err := rows.Scan(&claimID, &v.VoterUserID, &v.Vote, &v.VotedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if i, ok := byID[claimID]; ok {
+			d.Claims[i].Votes = append(d.Claims[i].Votes, v)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = s.PG.Query(ctx, `
+		SELECT `+scoreEventColumns+`
+		FROM score_events
+		WHERE round_id = $1
+		ORDER BY created_at ASC
+	`, roundID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		e, err := scanScoreEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		d.ScoreEvents = append(d.ScoreEvents, *e)
+	}
+	return d, rows.Err()
+}
+
+type PlayerSummary struct {
+	UserID         string `json:"userId"`
+	DisplayName    string `json:"displayName"`
+	Score          int    `json:"score"`
+	RoundsPlayed   int    `json:"roundsPlayed"`
+	CorrectGuesses int    `json:"correctGuesses"`
+}
+
+type GameSummary struct {
+	// GameID is the game the summary covers; nil for the room's whole history.
+	GameID  *string         `json:"gameId,omitempty"`
+	Rounds  int             `json:"rounds"`
+	Players []PlayerSummary `json:"players"`
+}
+
+// GetGameSummary totals every member's results, highest score first. With a
+// gameID only that game's rounds and score events count, so scores match
+// GamePodium; without one it covers the room's whole history and scores are
+// the points earned, ignoring resets. It returns pgx.ErrNoRows if the game is
+// not one of the room's.
+func (s *Storage) GetGameSummary(ctx context.Context, roomID string, gameID *string) (*GameSummary, error) {
+	sum := &GameSummary{GameID: gameID, Players: []PlayerSummary{}}
+	if gameID != nil {
+		var ok bool
+		err := s.PG.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM games WHERE id=$1 AND room_id=$2)`, *gameID, roomID).Scan(&ok)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "22P02" {
+			return nil, pgx.ErrNoRows
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, pgx.ErrNoRows
+		}
+	}
+
+	if err := s.PG.QueryRow(ctx, `
+		SELECT COUNT(*) FROM room_rounds WHERE room_id=$1 AND ($2::uuid IS NULL OR game_id=$2)
+	`, roomID, gameID).Scan(&sum.Rounds); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.PG.Query(ctx, `
+		SELECT
+			m.user_id,
+			m.display_name,
+			COALESCE((SELECT SUM(se.delta) FROM score_events se
+			  WHERE se.room_id = m.room_id AND se.user_id = m.user_id
+			    AND CASE WHEN $2::uuid IS NULL THEN se.reason <> $3 ELSE se.game_id = $2 END), 0)::int AS score,
+			(SELECT COUNT(*) FROM round_assignments ra
+			   JOIN room_rounds rr ON rr.id = ra.round_id
+			  WHERE rr.room_id = m.room_id AND ra.user_id = m.user_id
+			    AND ($2::uuid IS NULL OR rr.game_id = $2)),
+			(SELECT COUNT(*) FROM round_claims rc
+			   JOIN room_rounds rr ON rr.id = rc.round_id
+			  WHERE rc.room_id = m.room_id AND rc.claimant_user_id = m.user_id AND rc.status = 'approved'
+			    AND ($2::uuid IS NULL OR rr.game_id = $2))
+		FROM room_members m
+		WHERE m.room_id = $1
+		ORDER BY score DESC, m.joined_at ASC
+	`, roomID, gameID, ReasonReset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p PlayerSummary
+		if err := rows.Scan(&p.UserID, &p.DisplayName, &p.Score, &p.RoundsPlayed, &p.CorrectGuesses); err != nil {
+			return nil, err
+		}
+		sum.Players = append(sum.Players, p)
+	}
+	return sum, rows.Err()
+}
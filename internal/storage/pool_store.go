@@ -0,0 +1,143 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrPackNotFound = errors.New("pack not found")
+
+// PackPool counts the characters of one of the room's selected packs and how
+// many of them have not been used in the room yet.
+type PackPool struct {
+	Slug      string `json:"slug"`
+	Total     int    `json:"total"`
+	Remaining int    `json:"remaining"`
+}
+
+// RoomPool is the character pool the next round draws from.
+type RoomPool struct {
+	Lang           string     `json:"lang"`
+	TranslatedOnly bool       `json:"translatedOnly"` // characters without a Lang name are left out
+	Remaining      int        `json:"remaining"`
+	Packs          []PackPool `json:"packs"`
+}
+
+// GetRoomPool counts the characters of every pack selected in the room. With
+// translatedOnly, characters without a name in lang are not counted, matching
+// what StartRoundAssignCharacters would draw from.
+func (s *Storage) GetRoomPool(ctx context.Context, roomID, lang string, translatedOnly bool) (*RoomPool, error) {
+	rows, err := s.PG.Query(ctx, `
+		SELECT
+			p.slug,
+			COUNT(c.id)::int,
+			COUNT(c.id) FILTER (WHERE u.character_id IS NULL)::int
+		FROM room_pack_selection rps
+		JOIN packs p ON p.id = rps.pack_id
+		LEFT JOIN characters c ON c.pack_id = p.id
+		  AND (NOT $3 OR EXISTS (
+			SELECT 1 FROM character_translations ct
+			WHERE ct.character_id = c.id AND ct.lang = $2
+		  ))
+		LEFT JOIN room_used_characters u ON u.room_id = rps.room_id AND u.character_id = c.id
+		WHERE rps.room_id = $1
+		GROUP BY p.slug
+		ORDER BY p.slug ASC
+	`, roomID, lang, translatedOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pool := &RoomPool{Lang: lang, TranslatedOnly: translatedOnly, Packs: []PackPool{}}
+	for rows.Next() {
+		var p PackPool
+		if err := rows.Scan(&p.Slug, &p.Total, &p.Remaining); err != nil {
+			return nil, err
+		}
+		pool.Remaining += p.Remaining
+		pool.Packs = append(pool.Packs, p)
+	}
+	return pool, rows.Err()
+}
+
+// CountRoundPlayers returns how many of the connected users would get a
+// character if a round started now: spectators never do, and the host only
+// when the room says so.
+func (s *Storage) CountRoundPlayers(ctx context.Context, roomID string, settings RoomSettings, connected []string) (int, error) {
+	var n int
+	err := s.PG.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM room_members
+		WHERE room_id = $1
+		  AND user_id = ANY($3)
+		  AND role <> 'spectator'
+		  AND (role <> 'host' OR $2)
+	`, roomID, settings.HostPlays, connected).Scan(&n)
+	return n, err
+}
+
+// ResetCharacterPool makes used characters available again in the room. With
+// packSlugs only the characters of those packs are released; a slug that is not
+// one of the room's selected packs fails with ErrPackNotFound. It returns how
+// many characters were released.
+func (s *Storage) ResetCharacterPool(ctx context.Context, roomID string, packSlugs []string) (int64, error) {
+	query := `DELETE FROM room_used_characters WHERE room_id=$1`
+	args := []any{roomID}
+	if len(packSlugs) > 0 {
+		selected, err := s.GetRoomSelectedPackSlugs(ctx, roomID)
+		if err != nil {
+			return 0, err
+		}
+		isSelected := make(map[string]bool, len(selected))
+		for _, slug := range selected {
+			isSelected[slug] = true
+		}
+		for _, slug := range packSlugs {
+			if !isSelected[slug] {
+				return 0, fmt.Errorf("%w: %s is not selected in this room", ErrPackNotFound, slug)
+			}
+		}
+
+		query = `
+			DELETE FROM room_used_characters u
+			USING characters c, packs p
+			WHERE u.room_id = $1 AND c.id = u.character_id AND p.id = c.pack_id AND p.slug = ANY($2)
+		`
+		args = append(args, packSlugs)
+	}
+
+	tag, err := s.PG.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	_, _ = s.PG.Exec(ctx, `UPDATE rooms SET last_activity_at=now() WHERE id=$1`, roomID)
+	return tag.RowsAffected(), nil
+}
+
+// ResetScores sets every member's score in the room back to zero. The reset is
+// recorded in the score ledger.
+func (s *Storage) ResetScores(ctx context.Context, roomID, actorUserID string) (err error) {
+	tx, err := s.PG.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	if _, err = tx.Exec(ctx, `SELECT 1 FROM rooms WHERE id=$1 FOR UPDATE`, roomID); err != nil {
+		return err
+	}
+	if err = resetScores(ctx, tx, roomID, &actorUserID); err != nil {
+		return err
+	}
+	_, _ = tx.Exec(ctx, `UPDATE rooms SET last_activity_at=now() WHERE id=$1`, roomID)
+
+	return tx.Commit(ctx)
+}
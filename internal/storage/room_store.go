@@ -88,14 +88,23 @@ func (s *Storage) JoinRoom(ctx context.Context, roomID string, userID string, di
 		return pgx.ErrNoRows
 	}
 
-	_, err = tx.Exec(ctx, `
+	if err = checkSeat(ctx, tx, roomID, userID, ""); err != nil {
+		return err
+	}
+
+	tag, err := tx.Exec(ctx, `
 		INSERT INTO room_members (room_id, user_id, display_name, role, score)
-		VALUES ($1, $2, $3, 'player', 0)
+		SELECT $1, $2, $3, 'player', 0
+		WHERE NOT EXISTS (SELECT 1 FROM room_bans WHERE room_id = $1 AND user_id = $2)
 		ON CONFLICT (room_id, user_id) DO UPDATE SET display_name = EXCLUDED.display_name
 	`, roomID, userID, displayName)
 	if err != nil {
 		return err
 	}
+	if tag.RowsAffected() == 0 {
+		err = ErrBanned
+		return err
+	}
 
 	_, err = tx.Exec(ctx, `UPDATE rooms SET last_activity_at = now() WHERE id=$1`, roomID)
 	if err != nil {
@@ -128,13 +137,124 @@ func (s *Storage) ListRoomMembers(ctx context.Context, roomID string) ([]RoomMem
 	return out, rows.Err()
 }
 
-func (s *Storage) UpsertRoomMember(ctx context.Context, roomID, userID, displayName, role string) error {
-	_, err := s.PG.Exec(ctx, `
+func (s *Storage) GetRoomMember(ctx context.Context, roomID, userID string) (*RoomMember, error) {
+	var m RoomMember
+	err := s.PG.QueryRow(ctx, `
+		SELECT user_id, display_name, role, score, joined_at
+		FROM room_members
+		WHERE room_id = $1 AND user_id = $2
+	`, roomID, userID).Scan(&m.UserID, &m.DisplayName, &m.Role, &m.Score, &m.JoinedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// UpsertRoomMember adds or updates a member. It returns ErrBanned if the user
+// is banned from the room and ErrRoomFull if there is no player seat left.
+func (s *Storage) UpsertRoomMember(ctx context.Context, roomID, userID, displayName, role string) (err error) {
+	tx, err := s.PG.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	// Lock the room so concurrent joins cannot both take the last seat.
+	if _, err = tx.Exec(ctx, `SELECT 1 FROM rooms WHERE id=$1 FOR UPDATE`, roomID); err != nil {
+		return err
+	}
+
+	if err = checkSeat(ctx, tx, roomID, userID, role); err != nil {
+		return err
+	}
+
+	tag, err := tx.Exec(ctx, `
 		INSERT INTO room_members (room_id, user_id, display_name, role, score)
-		VALUES ($1, $2, $3, $4, 0)
+		SELECT $1, $2, $3, $4, 0
+		WHERE NOT EXISTS (SELECT 1 FROM room_bans WHERE room_id = $1 AND user_id = $2)
 		ON CONFLICT (room_id, user_id) DO UPDATE
 		SET display_name = EXCLUDED.display_name,
 		    role = EXCLUDED.role
 	`, roomID, userID, displayName, role)
-	return err
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		err = ErrBanned
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// CanHost reports whether a member with role may be handed the room. Spectators
+// never host, and the host already does.
+func CanHost(role string) bool { return role == "player" }
+
+// TransferRoomOwnership makes toUserID the room owner and host member, demoting
+// fromUserID to player. It returns ErrNotHost if fromUserID no longer owns the
+// room, so concurrent transfers (e.g. two instances failing over) cannot both
+// succeed, pgx.ErrNoRows if toUserID is not a member and ErrCannotHost if
+// toUserID is not a player.
+func (s *Storage) TransferRoomOwnership(ctx context.Context, roomID, fromUserID, toUserID string) (err error) {
+	tx, err := s.PG.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	var owner string
+	if err = tx.QueryRow(ctx, `SELECT owner_user_id FROM rooms WHERE id=$1 FOR UPDATE`, roomID).Scan(&owner); err != nil {
+		return err
+	}
+	if owner != fromUserID {
+		return ErrNotHost
+	}
+
+	var role string
+	if err = tx.QueryRow(ctx, `
+		SELECT role FROM room_members
+		WHERE room_id = $1 AND user_id = $2
+		FOR UPDATE
+	`, roomID, toUserID).Scan(&role); err != nil {
+		return err
+	}
+	if !CanHost(role) {
+		err = ErrCannotHost
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE room_members SET role = 'host'
+		WHERE room_id = $1 AND user_id = $2
+	`, roomID, toUserID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE room_members SET role = 'player'
+		WHERE room_id = $1 AND user_id = $2
+	`, roomID, fromUserID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE rooms SET owner_user_id = $2, last_activity_at = now()
+		WHERE id = $1
+	`, roomID, toUserID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
 }
@@ -0,0 +1,307 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type Round struct {
+	ID        string     `json:"id"`
+	RoomID    string     `json:"roomId"`
+	GameID    *string    `json:"gameId,omitempty"`
+	Lang      string     `json:"lang"`
+	StartedAt time.Time  `json:"startedAt"`
+	EndsAt    *time.Time `json:"endsAt,omitempty"`
+	EndedAt   *time.Time `json:"endedAt,omitempty"`
+
+	// How characters were drawn; ReplayRoundAssignment re-runs the draw from
+	// these and the inputs recorded with them.
+	AssignmentStrategy *string `json:"assignmentStrategy,omitempty"`
+	AssignmentSeed     *int64  `json:"assignmentSeed,omitempty"`
+}
+
+const roundColumns = `id, room_id, game_id, lang, started_at, ends_at, ended_at, assignment_strategy, assignment_seed`
+
+func scanRound(row pgx.Row) (*Round, error) {
+	var r Round
+	if err := row.Scan(&r.ID, &r.RoomID, &r.GameID, &r.Lang, &r.StartedAt, &r.EndsAt, &r.EndedAt, &r.AssignmentStrategy, &r.AssignmentSeed); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// GetRound returns pgx.ErrNoRows for unknown or malformed round IDs.
+func (s *Storage) GetRound(ctx context.Context, roundID string) (*Round, error) {
+	r, err := scanRound(s.PG.QueryRow(ctx, `SELECT `+roundColumns+` FROM room_rounds WHERE id=$1`, roundID))
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "22P02" {
+		return nil, pgx.ErrNoRows
+	}
+	return r, err
+}
+
+// ExpiredRound is a timed round ended by EndExpiredRounds, with the claims that
+// were still open when it ended.
+type ExpiredRound struct {
+	RoomID   string
+	RoomCode string
+	RoundID  string
+	Claims   []ExpiredClaim
+}
+
+// EndExpiredRounds ends up to limit rounds whose deadline has passed. Deadlines
+// live in the database, so timers survive restarts, and rooms locked by another
+// instance are skipped so each round is ended exactly once.
+func (s *Storage) EndExpiredRounds(ctx context.Context, limit int) (out []ExpiredRound, err error) {
+	tx, err := s.PG.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	rows, err := tx.Query(ctx, `
+		SELECT r.id, r.code, rr.id
+		FROM room_rounds rr
+		JOIN rooms r ON r.current_round_id = rr.id
+		WHERE rr.ended_at IS NULL AND rr.ends_at IS NOT NULL AND rr.ends_at <= now()
+		ORDER BY rr.ends_at ASC
+		LIMIT $1
+		FOR UPDATE OF r SKIP LOCKED
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var e ExpiredRound
+		if err = rows.Scan(&e.RoomID, &e.RoomCode, &e.RoundID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range out {
+		if out[i].Claims, err = endRound(ctx, tx, out[i].RoomID, out[i].RoundID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RoundResult is one player's outcome in a finished round.
+type RoundResult struct {
+	UserID     string            `json:"userId"`
+	Character  AssignedCharacter `json:"character"`
+	Guessed    bool              `json:"guessed"`
+	GuessOrder int               `json:"guessOrder,omitempty"` // 1 for the first correct guess of the round, ...
+	ScoreDelta int               `json:"scoreDelta"`
+}
+
+type RoundSummary struct {
+	Round   *Round        `json:"round"`
+	Results []RoundResult `json:"results"`
+	// ScoreDeltas holds the net points every user (players, voters, the host)
+	// gained or lost during the round, undos included.
+	ScoreDeltas map[string]int `json:"scoreDeltas"`
+}
+
+// GetRoundSummary reveals who had which character in a round, who guessed it
+// and how scores moved.
+func (s *Storage) GetRoundSummary(ctx context.Context, roundID string) (*RoundSummary, error) {
+	round, err := s.GetRound(ctx, roundID)
+	if err != nil {
+		return nil, err
+	}
+
+	assigns, err := s.ListRoundAssignments(ctx, roundID)
+	if err != nil {
+		return nil, err
+	}
+
+	guessOrder := map[string]int{}
+	rows, err := s.PG.Query(ctx, `
+		SELECT claimant_user_id
+		FROM round_claims
+		WHERE round_id = $1 AND status = 'approved'
+		ORDER BY resolved_at ASC
+	`, roundID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var uid string
+		if err := rows.Scan(&uid); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if _, ok := guessOrder[uid]; !ok {
+			guessOrder[uid] = len(guessOrder) + 1
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	deltas := map[string]int{}
+	rows, err = s.PG.Query(ctx, `
+		SELECT user_id, SUM(delta)::int
+		FROM score_events
+		WHERE round_id = $1
+		GROUP BY user_id
+		HAVING SUM(delta) <> 0
+	`, roundID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var uid string
+		var d int
+		if err := rows.Scan(&uid, &d); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		deltas[uid] = d
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	results := make([]RoundResult, 0, len(assigns))
+	for _, a := range assigns {
+		order := guessOrder[a.UserID]
+		results = append(results, RoundResult{
+			UserID:     a.UserID,
+			Character:  a.Character,
+			Guessed:    order > 0,
+			GuessOrder: order,
+			ScoreDelta: deltas[a.UserID],
+		})
+	}
+
+	return &RoundSummary{Round: round, Results: results, ScoreDeltas: deltas}, nil
+}
+
+var ErrDrawNotRecorded = errors.New("round draw was not recorded")
+
+// ReplayRoundAssignment re-runs a round's character draw from its recorded
+// strategy, seed and inputs. Comparing the result with ListRoundAssignments
+// shows whether the round was drawn as recorded. Characters deleted or re-rated
+// since the round can make the replay fail or differ.
+func (s *Storage) ReplayRoundAssignment(ctx context.Context, roundID string) ([]RoundAssignment, error) {
+	var (
+		strategyName *string
+		seed         *int64
+		players      []string
+		candidateIDs []string
+		rawParams    []byte
+		lang         string
+	)
+	err := s.PG.QueryRow(ctx, `
+		SELECT assignment_strategy, assignment_seed, assignment_players::text[], assignment_candidates::text[],
+		       assignment_params, lang
+		FROM room_rounds
+		WHERE id=$1
+	`, roundID).Scan(&strategyName, &seed, &players, &candidateIDs, &rawParams, &lang)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "22P02" {
+		return nil, pgx.ErrNoRows
+	}
+	if err != nil {
+		return nil, err
+	}
+	if strategyName == nil || seed == nil || candidateIDs == nil || rawParams == nil {
+		return nil, ErrDrawNotRecorded
+	}
+	strategy, ok := AssignmentStrategyByName(*strategyName)
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown strategy %q", ErrDrawNotRecorded, *strategyName)
+	}
+
+	var params assignmentParams
+	if err := json.Unmarshal(rawParams, &params); err != nil {
+		return nil, err
+	}
+	in := AssignmentInput{
+		Players:     players,
+		Difficulty:  params.Difficulty,
+		PackBalance: params.PackBalance,
+		UniquePacks: params.UniquePacks,
+	}
+	if len(params.Seen) > 0 {
+		in.Seen = map[string]map[string]bool{}
+		for uid, ids := range params.Seen {
+			in.Seen[uid] = map[string]bool{}
+			for _, id := range ids {
+				in.Seen[uid][id] = true
+			}
+		}
+	}
+
+	rows, err := s.PG.Query(ctx, `
+		SELECT
+			c.id,
+			COALESCE(ct_req.name, ct_es.name, ct_en.name, c.canonical_key) AS name,
+			c.pack_id,
+			COALESCE(c.difficulty, 0)
+		FROM characters c
+		LEFT JOIN character_translations ct_req ON ct_req.character_id = c.id AND ct_req.lang = $2
+		LEFT JOIN character_translations ct_es  ON ct_es.character_id  = c.id AND ct_es.lang  = 'es'
+		LEFT JOIN character_translations ct_en  ON ct_en.character_id  = c.id AND ct_en.lang  = 'en'
+		WHERE c.id = ANY($1::uuid[])
+	`, candidateIDs, lang)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]Candidate, len(candidateIDs))
+	for rows.Next() {
+		var c Candidate
+		if err := rows.Scan(&c.ID, &c.Name, &c.PackID, &c.Difficulty); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		byID[c.ID] = c
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	in.Candidates = make([]Candidate, 0, len(candidateIDs))
+	for _, id := range candidateIDs {
+		c, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: character %s no longer exists", ErrDrawNotRecorded, id)
+		}
+		in.Candidates = append(in.Candidates, c)
+	}
+
+	picked, err := AssignCharacters(strategy, *seed, in)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]RoundAssignment, len(players))
+	for i, uid := range players {
+		out[i] = RoundAssignment{UserID: uid, Character: AssignedCharacter{ID: picked[i].ID, Name: picked[i].Name}}
+	}
+	return out, nil
+}
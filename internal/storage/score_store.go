@@ -0,0 +1,208 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrNothingToUndo = errors.New("no score change to undo")
+
+const (
+	ReasonUndo  = "undo"
+	ReasonReset = "reset"
+	// ReasonInitial marks the entries migration 000003 seeded with the scores
+	// members had before the ledger existed.
+	ReasonInitial = "initial"
+)
+
+type ScoreEvent struct {
+	ID             string     `json:"id"`
+	RoomID         string     `json:"roomId"`
+	RoundID        *string    `json:"roundId,omitempty"`
+	UserID         string     `json:"userId"`
+	Delta          int        `json:"delta"`
+	Reason         string     `json:"reason"`
+	ActorUserID    *string    `json:"actorUserId,omitempty"`
+	ClaimID        *string    `json:"claimId,omitempty"`
+	RevertsEventID *string    `json:"revertsEventId,omitempty"`
+	RevertedAt     *time.Time `json:"revertedAt,omitempty"`
+	GameID         *string    `json:"gameId,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+const scoreEventColumns = `id, room_id, round_id, user_id, delta, reason, actor_user_id, claim_id, reverts_event_id, reverted_at, game_id, created_at`
+
+func scanScoreEvent(row pgx.Row) (*ScoreEvent, error) {
+	var e ScoreEvent
+	if err := row.Scan(
+		&e.ID, &e.RoomID, &e.RoundID, &e.UserID, &e.Delta, &e.Reason,
+		&e.ActorUserID, &e.ClaimID, &e.RevertsEventID, &e.RevertedAt, &e.GameID, &e.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// recordScore appends ev to the ledger and applies its delta to room_members.score.
+// Entries without a GameID are attributed to the room's active game, if any.
+// Callers must run it inside the transaction that decided the change.
+func recordScore(ctx context.Context, tx pgx.Tx, ev ScoreEvent) (*ScoreEvent, error) {
+	out, err := scanScoreEvent(tx.QueryRow(ctx, `
+		INSERT INTO score_events (room_id, round_id, user_id, delta, reason, actor_user_id, claim_id, reverts_event_id, game_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8,
+		        COALESCE($9::uuid, (SELECT id FROM games WHERE room_id=$1 AND status='active')))
+		RETURNING `+scoreEventColumns,
+		ev.RoomID, ev.RoundID, ev.UserID, ev.Delta, ev.Reason, ev.ActorUserID, ev.ClaimID, ev.RevertsEventID, ev.GameID))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE room_members
+		SET score = score + $3
+		WHERE room_id=$1 AND user_id=$2
+	`, ev.RoomID, ev.UserID, ev.Delta); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// resetScores sets every member's score back to zero by appending a "reset" entry
+// for each non-zero score, so the ledger still explains room_members.score.
+func resetScores(ctx context.Context, tx pgx.Tx, roomID string, actorUserID *string) error {
+	rows, err := tx.Query(ctx, `
+		SELECT user_id::text, score
+		FROM room_members
+		WHERE room_id=$1 AND score<>0
+		FOR UPDATE
+	`, roomID)
+	if err != nil {
+		return err
+	}
+	type memberScore struct {
+		userID string
+		score  int
+	}
+	var scores []memberScore
+	for rows.Next() {
+		var m memberScore
+		if err := rows.Scan(&m.userID, &m.score); err != nil {
+			rows.Close()
+			return err
+		}
+		scores = append(scores, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, m := range scores {
+		if _, err := recordScore(ctx, tx, ScoreEvent{
+			RoomID:      roomID,
+			UserID:      m.userID,
+			Delta:       -m.score,
+			Reason:      ReasonReset,
+			ActorUserID: actorUserID,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UndoLastScoreEvent reverts the most recent ledger entry of the room that has not
+// been reverted yet by appending a compensating "undo" entry. Entries older than
+// the last score reset or game start cannot be undone, nor can the seeded
+// "initial" entries.
+func (s *Storage) UndoLastScoreEvent(ctx context.Context, roomID, actorUserID string) (undo *ScoreEvent, err error) {
+	tx, err := s.PG.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	if _, err = tx.Exec(ctx, `SELECT 1 FROM rooms WHERE id=$1 FOR UPDATE`, roomID); err != nil {
+		return nil, err
+	}
+
+	last, err := scanScoreEvent(tx.QueryRow(ctx, `
+		SELECT `+scoreEventColumns+`
+		FROM score_events
+		WHERE room_id=$1 AND reason NOT IN ($2, $3, $4) AND reverted_at IS NULL
+		  AND created_at > GREATEST(
+		        (SELECT max(created_at) FROM score_events WHERE room_id=$1 AND reason=$3),
+		        (SELECT max(started_at) FROM games WHERE room_id=$1),
+		        '-infinity'::timestamptz)
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, roomID, ReasonUndo, ReasonReset, ReasonInitial))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			err = ErrNothingToUndo
+		}
+		return nil, err
+	}
+
+	if undo, err = recordScore(ctx, tx, ScoreEvent{
+		RoomID:         roomID,
+		RoundID:        last.RoundID,
+		UserID:         last.UserID,
+		Delta:          -last.Delta,
+		Reason:         ReasonUndo,
+		ActorUserID:    &actorUserID,
+		ClaimID:        last.ClaimID,
+		RevertsEventID: &last.ID,
+		GameID:         last.GameID,
+	}); err != nil {
+		return nil, err
+	}
+
+	if _, err = tx.Exec(ctx, `UPDATE score_events SET reverted_at=now() WHERE id=$1`, last.ID); err != nil {
+		return nil, err
+	}
+	_, _ = tx.Exec(ctx, `UPDATE rooms SET last_activity_at=now() WHERE id=$1`, roomID)
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return undo, nil
+}
+
+func (s *Storage) ListScoreEvents(ctx context.Context, roomID string, limit, offset int) ([]ScoreEvent, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	rows, err := s.PG.Query(ctx, `
+		SELECT `+scoreEventColumns+`
+		FROM score_events
+		WHERE room_id=$1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`, roomID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []ScoreEvent{}
+	for rows.Next() {
+		e, err := scanScoreEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *e)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"context"
+	"sort"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	ReasonCorrectGuess  = "correct_guess"
+	ReasonOrdinalBonus  = "ordinal_bonus"
+	ReasonRejectedClaim = "rejected_claim"
+	ReasonMajorityVote  = "majority_vote"
+	ReasonManual        = "manual"
+)
+
+type ScoreDelta struct {
+	UserID string `json:"userId"`
+	Delta  int    `json:"delta"`
+	Reason string `json:"reason"`
+}
+
+// ClaimDeltas computes the score changes produced by a resolved claim. ordinal is
+// the zero-based position of this correct guess within the round and votes maps
+// voter user id to "yes"/"no".
+func (p ScoringPolicy) ClaimDeltas(c *Claim, ordinal int, votes map[string]string) []ScoreDelta {
+	if !p.Auto {
+		return nil
+	}
+
+	var out []ScoreDelta
+	add := func(userID string, delta int, reason string) {
+		if delta != 0 {
+			out = append(out, ScoreDelta{UserID: userID, Delta: delta, Reason: reason})
+		}
+	}
+
+	var majority string
+	switch c.Status {
+	case ClaimApproved:
+		majority = VoteYes
+		add(c.ClaimantUserID, p.CorrectGuess, ReasonCorrectGuess)
+		if ordinal >= 0 && ordinal < len(p.OrdinalBonus) {
+			add(c.ClaimantUserID, p.OrdinalBonus[ordinal], ReasonOrdinalBonus)
+		}
+	case ClaimRejected:
+		majority = VoteNo
+		add(c.ClaimantUserID, -p.RejectedPenalty, ReasonRejectedClaim)
+	default:
+		return out
+	}
+
+	voters := make([]string, 0, len(votes))
+	for uid, v := range votes {
+		if v == majority {
+			voters = append(voters, uid)
+		}
+	}
+	sort.Strings(voters)
+	for _, uid := range voters {
+		add(uid, p.MajorityVoter, ReasonMajorityVote)
+	}
+	return out
+}
+
+// applyClaimScoring runs the room's scoring policy for a claim that was just
+// resolved inside tx and records the resulting changes in the score ledger.
+func applyClaimScoring(ctx context.Context, tx pgx.Tx, c *Claim) ([]ScoreDelta, error) {
+	settings, err := getRoomSettings(ctx, tx, c.RoomID)
+	if err != nil {
+		return nil, err
+	}
+	if !settings.Scoring.Auto {
+		return nil, nil
+	}
+
+	var ordinal int
+	if err := tx.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM round_claims
+		WHERE round_id=$1 AND status='approved' AND id<>$2 AND resolved_at <= $3
+	`, c.RoundID, c.ID, c.ResolvedAt).Scan(&ordinal); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, `SELECT voter_user_id, vote FROM round_claim_votes WHERE claim_id=$1`, c.ID)
+	if err != nil {
+		return nil, err
+	}
+	votes := map[string]string{}
+	for rows.Next() {
+		var uid, v string
+		if err := rows.Scan(&uid, &v); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		votes[uid] = v
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	deltas := settings.Scoring.ClaimDeltas(c, ordinal, votes)
+	for _, d := range deltas {
+		if _, err := recordScore(ctx, tx, ScoreEvent{
+			RoomID:  c.RoomID,
+			RoundID: &c.RoundID,
+			UserID:  d.UserID,
+			Delta:   d.Delta,
+			Reason:  d.Reason,
+			ClaimID: &c.ID,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return deltas, nil
+}
@@ -0,0 +1,242 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrInvalidSettings = errors.New("invalid room settings")
+	ErrRoomFull        = errors.New("room is full")
+)
+
+type ScoringPolicy struct {
+	// Auto applies the policy whenever a claim resolves. When false the host
+	// awards every point by hand with host:score_add.
+	Auto bool `json:"auto"`
+
+	CorrectGuess    int   `json:"correctGuess"`
+	OrdinalBonus    []int `json:"ordinalBonus"` // extra points for the 1st, 2nd, ... correct guess of a round
+	RejectedPenalty int   `json:"rejectedPenalty"`
+	MajorityVoter   int   `json:"majorityVoter"`
+}
+
+type RoomSettings struct {
+	Lang                 string `json:"lang"`                 // language rounds are played in
+	RoundDurationSeconds int    `json:"roundDurationSeconds"` // 0 means rounds last until the host ends them
+	MaxPlayers           int    `json:"maxPlayers"`           // 0 means unlimited; spectators and the host don't count
+	ClaimTimeoutSeconds  int    `json:"claimTimeoutSeconds"`  // how long a claim stays open for votes
+
+	Scoring ScoringPolicy `json:"scoring"`
+
+	// HostPlays gives the host a character like everyone else. Turn it off for
+	// game-master style rooms where the host only moderates.
+	HostPlays bool `json:"hostPlays"`
+
+	// SpectatorsSeeAll shows spectators every player's character, e.g. for a
+	// streamer's overlay. Otherwise they only follow round progress.
+	SpectatorsSeeAll bool `json:"spectatorsSeeAll"`
+
+	// TranslatedOnly leaves characters without a name in the round's language
+	// out of the draw instead of falling back to another language.
+	TranslatedOnly bool `json:"translatedOnly"`
+
+	// AssignmentStrategy names how characters are drawn each round; see
+	// AssignmentStrategyByName. Difficulty (1 easy to 3 hard) is the level the
+	// "difficulty" strategy favours.
+	AssignmentStrategy string `json:"assignmentStrategy"`
+	Difficulty         int    `json:"difficulty"`
+
+	// PackBalance is how the "balanced" strategy spreads a round over the
+	// selected packs: "round_robin" or "random_pack". UniquePacks gives every
+	// player of a round a character from a different pack.
+	PackBalance string `json:"packBalance"`
+	UniquePacks bool   `json:"uniquePacks"`
+}
+
+func DefaultRoomSettings() RoomSettings {
+	return RoomSettings{
+		Lang:                "es",
+		ClaimTimeoutSeconds: 30,
+		HostPlays:           true,
+		AssignmentStrategy:  AssignUniform,
+		Difficulty:          2,
+		PackBalance:         PackRoundRobin,
+		Scoring: ScoringPolicy{
+			Auto:            true,
+			CorrectGuess:    3,
+			OrdinalBonus:    []int{2, 1},
+			RejectedPenalty: 1,
+			MajorityVoter:   1,
+		},
+	}
+}
+
+func (rs RoomSettings) ClaimTimeout() time.Duration {
+	return time.Duration(rs.ClaimTimeoutSeconds) * time.Second
+}
+
+func (rs RoomSettings) RoundDuration() time.Duration {
+	return time.Duration(rs.RoundDurationSeconds) * time.Second
+}
+
+// Validate reports the first out-of-range setting, wrapped in ErrInvalidSettings.
+func (rs RoomSettings) Validate() error {
+	invalid := func(msg string) error {
+		return fmt.Errorf("%w: %s", ErrInvalidSettings, msg)
+	}
+
+	switch {
+	case rs.Lang == "" || len(rs.Lang) > 10:
+		return invalid("lang must be a language code")
+	case rs.RoundDurationSeconds < 0 || rs.RoundDurationSeconds > 3600:
+		return invalid("roundDurationSeconds must be between 0 and 3600")
+	case rs.MaxPlayers < 0 || rs.MaxPlayers > 100:
+		return invalid("maxPlayers must be between 0 and 100")
+	case rs.ClaimTimeoutSeconds < 5 || rs.ClaimTimeoutSeconds > 300:
+		return invalid("claimTimeoutSeconds must be between 5 and 300")
+	case rs.Scoring.CorrectGuess < 0 || rs.Scoring.RejectedPenalty < 0 || rs.Scoring.MajorityVoter < 0:
+		return invalid("scoring points must not be negative")
+	case len(rs.Scoring.OrdinalBonus) > 10:
+		return invalid("ordinalBonus allows at most 10 entries")
+	case rs.Difficulty < 1 || rs.Difficulty > 3:
+		return invalid("difficulty must be between 1 and 3")
+	case rs.PackBalance != PackRoundRobin && rs.PackBalance != PackRandom:
+		return invalid("packBalance must be round_robin or random_pack")
+	case rs.UniquePacks && rs.AssignmentStrategy != AssignBalanced:
+		return invalid("uniquePacks requires the balanced assignmentStrategy")
+	}
+	if _, ok := AssignmentStrategyByName(rs.AssignmentStrategy); !ok {
+		return invalid("unknown assignmentStrategy")
+	}
+	for _, b := range rs.Scoring.OrdinalBonus {
+		if b < 0 {
+			return invalid("scoring points must not be negative")
+		}
+	}
+	return nil
+}
+
+func (s *Storage) GetRoomSettings(ctx context.Context, roomID string) (RoomSettings, error) {
+	return getRoomSettings(ctx, s.PG, roomID)
+}
+
+// checkSeat returns ErrRoomFull when userID would take a new player seat in a
+// room that has reached its MaxPlayers setting. role is the role the user joins
+// with; empty keeps an existing member's role. Callers must hold the room row
+// lock until the member is written, or two joins can both take the last seat.
+func checkSeat(ctx context.Context, q queryRower, roomID, userID, role string) error {
+	settings, err := getRoomSettings(ctx, q, roomID)
+	if err != nil {
+		return err
+	}
+	if settings.MaxPlayers <= 0 {
+		return nil
+	}
+
+	var current string
+	var players int
+	if err := q.QueryRow(ctx, `
+		SELECT
+			COALESCE(MAX(role) FILTER (WHERE user_id = $2), ''),
+			COUNT(*) FILTER (WHERE role = 'player')
+		FROM room_members
+		WHERE room_id = $1
+	`, roomID, userID).Scan(&current, &players); err != nil {
+		return err
+	}
+
+	if role == "" {
+		role = current
+		if role == "" {
+			role = "player"
+		}
+	}
+	if role != "player" || current == "player" {
+		return nil
+	}
+	if players >= settings.MaxPlayers {
+		return ErrRoomFull
+	}
+	return nil
+}
+
+func getRoomSettings(ctx context.Context, q queryRower, roomID string) (RoomSettings, error) {
+	rs := DefaultRoomSettings()
+
+	var raw []byte
+	err := q.QueryRow(ctx, `SELECT settings FROM room_settings WHERE room_id=$1`, roomID).Scan(&raw)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return rs, nil
+		}
+		return rs, err
+	}
+
+	// Stored documents only override the keys they contain.
+	if err := json.Unmarshal(raw, &rs); err != nil {
+		return DefaultRoomSettings(), err
+	}
+	return rs, nil
+}
+
+// UpdateRoomSettings applies a partial JSON document on top of the room's current
+// settings, validates the result and stores it.
+func (s *Storage) UpdateRoomSettings(ctx context.Context, roomID string, patch json.RawMessage) (rs RoomSettings, err error) {
+	tx, err := s.PG.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return rs, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	// Serialize concurrent updates of the same room.
+	if _, err = tx.Exec(ctx, `SELECT 1 FROM rooms WHERE id=$1 FOR UPDATE`, roomID); err != nil {
+		return rs, err
+	}
+	if rs, err = getRoomSettings(ctx, tx, roomID); err != nil {
+		return rs, err
+	}
+	if err = json.Unmarshal(patch, &rs); err != nil {
+		err = fmt.Errorf("%w: %v", ErrInvalidSettings, err)
+		return rs, err
+	}
+	if err = rs.Validate(); err != nil {
+		return rs, err
+	}
+	if err = saveRoomSettings(ctx, tx, roomID, rs); err != nil {
+		return rs, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return rs, err
+	}
+	return rs, nil
+}
+
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+func saveRoomSettings(ctx context.Context, q execer, roomID string, rs RoomSettings) error {
+	raw, err := json.Marshal(rs)
+	if err != nil {
+		return err
+	}
+	_, err = q.Exec(ctx, `
+		INSERT INTO room_settings (room_id, settings, updated_at)
+		VALUES ($1, $2, now())
+		ON CONFLICT (room_id) DO UPDATE
+		SET settings = EXCLUDED.settings, updated_at = EXCLUDED.updated_at
+	`, roomID, raw)
+	return err
+}
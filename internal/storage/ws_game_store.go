@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"errors"
+	"math/rand/v2"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -10,22 +11,56 @@ import (
 
 var (
 	ErrNotHost             = errors.New("not host")
+	ErrCannotHost          = errors.New("only players can become host")
 	ErrNoPackSelection     = errors.New("no packs selected for room")
 	ErrNotEnoughCharacters = errors.New("not enough characters available")
 	ErrRoundAlreadyActive  = errors.New("current round must be ended before starting a new one")
+	ErrNoEligiblePlayers   = errors.New("no eligible players connected")
 )
 
-func (s *Storage) AddMemberScore(ctx context.Context, roomID, userID string, delta int) error {
-	_, err := s.PG.Exec(ctx, `
-		UPDATE room_members
-		SET score = score + $3
-		WHERE room_id=$1 AND user_id=$2
-	`, roomID, userID, delta)
+// AddMemberScore records a manual score change by actorUserID in the ledger.
+func (s *Storage) AddMemberScore(ctx context.Context, roomID, userID string, delta int, actorUserID string) (ev *ScoreEvent, err error) {
+	tx, err := s.PG.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	_, _ = s.PG.Exec(ctx, `UPDATE rooms SET last_activity_at=now() WHERE id=$1`, roomID)
-	return nil
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	var cur *string
+	if err = tx.QueryRow(ctx, `SELECT current_round_id FROM rooms WHERE id=$1 FOR UPDATE`, roomID).Scan(&cur); err != nil {
+		return nil, err
+	}
+
+	var isMember bool
+	if err = tx.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM room_members WHERE room_id=$1 AND user_id=$2)
+	`, roomID, userID).Scan(&isMember); err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, pgx.ErrNoRows
+	}
+
+	if ev, err = recordScore(ctx, tx, ScoreEvent{
+		RoomID:      roomID,
+		RoundID:     cur,
+		UserID:      userID,
+		Delta:       delta,
+		Reason:      ReasonManual,
+		ActorUserID: &actorUserID,
+	}); err != nil {
+		return nil, err
+	}
+	_, _ = tx.Exec(ctx, `UPDATE rooms SET last_activity_at=now() WHERE id=$1`, roomID)
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return ev, nil
 }
 
 type AssignedCharacter struct {
@@ -43,10 +78,10 @@ func (s *Storage) StartRoundAssignCharacters(
 	roomID string,
 	lang string,
 	playerUserIDs []string,
-) (roundID string, assignments []RoundAssignment, err error) {
+) (roundID string, endsAt *time.Time, assignments []RoundAssignment, err error) {
 
 	if len(playerUserIDs) == 0 {
-		return "", nil, errors.New("no players to assign")
+		return "", nil, nil, errors.New("no players to assign")
 	}
 
 	packRows, err := s.PG.Query(ctx, `
@@ -55,7 +90,7 @@ func (s *Storage) StartRoundAssignCharacters(
 		WHERE room_id=$1
 	`, roomID)
 	if err != nil {
-		return "", nil, err
+		return "", nil, nil, err
 	}
 	defer packRows.Close()
 
@@ -63,20 +98,20 @@ func (s *Storage) StartRoundAssignCharacters(
 	for packRows.Next() {
 		var id string
 		if err := packRows.Scan(&id); err != nil {
-			return "", nil, err
+			return "", nil, nil, err
 		}
 		packIDs = append(packIDs, id)
 	}
 	if err := packRows.Err(); err != nil {
-		return "", nil, err
+		return "", nil, nil, err
 	}
 	if len(packIDs) == 0 {
-		return "", nil, ErrNoPackSelection
+		return "", nil, nil, ErrNoPackSelection
 	}
 
 	tx, err := s.PG.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
-		return "", nil, err
+		return "", nil, nil, err
 	}
 	defer func() {
 		if err != nil {
@@ -86,74 +121,76 @@ func (s *Storage) StartRoundAssignCharacters(
 
 	var cur *string
 	if err = tx.QueryRow(ctx, `SELECT current_round_id FROM rooms WHERE id=$1 FOR UPDATE`, roomID).Scan(&cur); err != nil {
-		return "", nil, err
+		return "", nil, nil, err
 	}
 	if cur != nil && *cur != "" {
-		return "", nil, ErrRoundAlreadyActive
+		return "", nil, nil, ErrRoundAlreadyActive
 	}
 
-	if err = tx.QueryRow(ctx, `
-		INSERT INTO room_rounds (room_id, started_at, lang)
-		VALUES ($1, now(), $2)
-		RETURNING id
-	`, roomID, lang).Scan(&roundID); err != nil {
-		return "", nil, err
+	settings, err := getRoomSettings(ctx, tx, roomID)
+	if err != nil {
+		return "", nil, nil, err
 	}
 
-	need := len(playerUserIDs)
-
-	type pick struct {
-		id   string
-		name string
+	if playerUserIDs, err = eligibleRoundPlayers(ctx, tx, roomID, playerUserIDs, settings); err != nil {
+		return "", nil, nil, err
 	}
-
-	rows, err := tx.Query(ctx, `
-		SELECT
-			c.id,
-			COALESCE(ct_req.name, ct_es.name, ct_en.name, c.canonical_key) AS name
-		FROM characters c
-		LEFT JOIN character_translations ct_req ON ct_req.character_id = c.id AND ct_req.lang = $3
-		LEFT JOIN character_translations ct_es  ON ct_es.character_id  = c.id AND ct_es.lang  = 'es'
-		LEFT JOIN character_translations ct_en  ON ct_en.character_id  = c.id AND ct_en.lang  = 'en'
-		WHERE c.pack_id = ANY($1)
-		  AND NOT EXISTS (
-			SELECT 1 FROM room_used_characters u
-			WHERE u.room_id = $2 AND u.character_id = c.id
-		  )
-		ORDER BY random()
-		LIMIT $4
-		FOR UPDATE OF c SKIP LOCKED
-	`, packIDs, roomID, lang, need)
-	if err != nil {
-		return "", nil, err
+	if len(playerUserIDs) == 0 {
+		err = ErrNoEligiblePlayers
+		return "", nil, nil, err
 	}
-	defer rows.Close()
 
-	var picked []pick
-	for rows.Next() {
-		var p pick
-		if err := rows.Scan(&p.id, &p.name); err != nil {
-			return "", nil, err
+	strategy, ok := AssignmentStrategyByName(settings.AssignmentStrategy)
+	if !ok {
+		strategy = uniformStrategy{}
+	}
+	in := AssignmentInput{
+		Players:     playerUserIDs,
+		Difficulty:  settings.Difficulty,
+		PackBalance: settings.PackBalance,
+		UniquePacks: settings.UniquePacks,
+	}
+	if in.Candidates, err = roundCandidates(ctx, tx, roomID, packIDs, lang, settings.TranslatedOnly); err != nil {
+		return "", nil, nil, err
+	}
+	if needsSeen(strategy) {
+		if in.Seen, err = seenCharacters(ctx, tx, roomID, playerUserIDs); err != nil {
+			return "", nil, nil, err
 		}
-		picked = append(picked, p)
 	}
-	if err := rows.Err(); err != nil {
-		return "", nil, err
+
+	seed := rand.Int64()
+	picked, err := AssignCharacters(strategy, seed, in)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	players, candidates, params, err := recordAssignmentInput(in)
+	if err != nil {
+		return "", nil, nil, err
 	}
 
-	if len(picked) < need {
-		return "", nil, ErrNotEnoughCharacters
+	// ends_at is NULL for untimed rounds; game_id links the round to the
+	// room's active game, if any.
+	if err = tx.QueryRow(ctx, `
+		INSERT INTO room_rounds (room_id, started_at, lang, ends_at, game_id,
+		                         assignment_strategy, assignment_seed, assignment_players, assignment_candidates, assignment_params)
+		VALUES ($1, now(), $2, CASE WHEN $3::int > 0 THEN now() + make_interval(secs => $3::int) END,
+		        (SELECT id FROM games WHERE room_id=$1 AND status='active'), $4, $5, $6, $7, $8)
+		RETURNING id, ends_at
+	`, roomID, lang, settings.RoundDurationSeconds, strategy.Name(), seed, players, candidates, params).Scan(&roundID, &endsAt); err != nil {
+		return "", nil, nil, err
 	}
 
 	for _, p := range picked {
 		if _, err = tx.Exec(ctx, `
 			INSERT INTO room_used_characters (room_id, character_id, first_used_at)
 			VALUES ($1, $2, now())
-		`, roomID, p.id); err != nil {
-			return "", nil, err
+		`, roomID, p.ID); err != nil {
+			return "", nil, nil, err
 		}
 	}
 
+	need := len(playerUserIDs)
 	assignments = make([]RoundAssignment, 0, need)
 	for i := 0; i < need; i++ {
 		userID := playerUserIDs[i]
@@ -162,15 +199,15 @@ func (s *Storage) StartRoundAssignCharacters(
 		if _, err = tx.Exec(ctx, `
 			INSERT INTO round_assignments (round_id, user_id, character_id, assigned_at)
 			VALUES ($1, $2, $3, now())
-		`, roundID, userID, ch.id); err != nil {
-			return "", nil, err
+		`, roundID, userID, ch.ID); err != nil {
+			return "", nil, nil, err
 		}
 
 		assignments = append(assignments, RoundAssignment{
 			UserID: userID,
 			Character: AssignedCharacter{
-				ID:   ch.id,
-				Name: ch.name,
+				ID:   ch.ID,
+				Name: ch.Name,
 			},
 		})
 	}
@@ -178,16 +215,145 @@ func (s *Storage) StartRoundAssignCharacters(
 	_, _ = tx.Exec(ctx, `UPDATE rooms SET current_round_id=$2, last_activity_at=now() WHERE id=$1`, roomID, roundID)
 
 	if err = tx.Commit(ctx); err != nil {
-		return "", nil, err
+		return "", nil, nil, err
 	}
 
-	return roundID, assignments, nil
+	return roundID, endsAt, assignments, nil
 }
 
-func (s *Storage) EndRound(ctx context.Context, roomID string) error {
+// roundCandidates lists the room's unused characters in the selected packs,
+// ordered by ID so a recorded seed replays the same draw. Names are translated
+// to lang; with translatedOnly, characters without a lang name are skipped.
+func roundCandidates(ctx context.Context, tx pgx.Tx, roomID string, packIDs []string, lang string, translatedOnly bool) ([]Candidate, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT
+			c.id,
+			COALESCE(ct_req.name, ct_es.name, ct_en.name, c.canonical_key) AS name,
+			c.pack_id,
+			COALESCE(c.difficulty, 0)
+		FROM characters c
+		LEFT JOIN character_translations ct_req ON ct_req.character_id = c.id AND ct_req.lang = $3
+		LEFT JOIN character_translations ct_es  ON ct_es.character_id  = c.id AND ct_es.lang  = 'es'
+		LEFT JOIN character_translations ct_en  ON ct_en.character_id  = c.id AND ct_en.lang  = 'en'
+		WHERE c.pack_id = ANY($1)
+		  AND NOT EXISTS (
+			SELECT 1 FROM room_used_characters u
+			WHERE u.room_id = $2 AND u.character_id = c.id
+		  )
+		  AND (NOT $4 OR ct_req.character_id IS NOT NULL)
+		ORDER BY c.id ASC
+	`, packIDs, roomID, lang, translatedOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Candidate
+	for rows.Next() {
+		var c Candidate
+		if err := rows.Scan(&c.ID, &c.Name, &c.PackID, &c.Difficulty); err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// seenCharacters returns, per user, the characters of every round they played
+// in other rooms: their own, revealed at the end, and everyone else's.
+func seenCharacters(ctx context.Context, tx pgx.Tx, roomID string, userIDs []string) (map[string]map[string]bool, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT DISTINCT me.user_id, ra.character_id
+		FROM round_assignments me
+		JOIN room_rounds rr ON rr.id = me.round_id
+		JOIN round_assignments ra ON ra.round_id = me.round_id
+		WHERE me.user_id = ANY($1) AND rr.room_id <> $2
+	`, userIDs, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seen := map[string]map[string]bool{}
+	for rows.Next() {
+		var userID, characterID string
+		if err := rows.Scan(&userID, &characterID); err != nil {
+			return nil, err
+		}
+		if seen[userID] == nil {
+			seen[userID] = map[string]bool{}
+		}
+		seen[userID][characterID] = true
+	}
+	return seen, rows.Err()
+}
+
+// eligibleRoundPlayers narrows candidates to the members who get a character:
+// spectators only watch, and the host only plays when the room says so.
+func eligibleRoundPlayers(ctx context.Context, tx pgx.Tx, roomID string, candidates []string, settings RoomSettings) ([]string, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT user_id
+		FROM room_members
+		WHERE room_id = $1 AND user_id = ANY($2)
+		  AND role <> 'spectator'
+		  AND (role <> 'host' OR $3)
+		ORDER BY joined_at ASC
+	`, roomID, candidates, settings.HostPlays)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out = append(out, id)
+	}
+	return out, rows.Err()
+}
+
+// ListRoundAssignments returns who got which character in a round, with names
+// translated to the round's language.
+func (s *Storage) ListRoundAssignments(ctx context.Context, roundID string) ([]RoundAssignment, error) {
+	rows, err := s.PG.Query(ctx, `
+		SELECT
+			ra.user_id,
+			c.id,
+			COALESCE(ct_req.name, ct_es.name, ct_en.name, c.canonical_key) AS name
+		FROM round_assignments ra
+		JOIN room_rounds rr ON rr.id = ra.round_id
+		JOIN characters c ON c.id = ra.character_id
+		LEFT JOIN character_translations ct_req ON ct_req.character_id = c.id AND ct_req.lang = rr.lang
+		LEFT JOIN character_translations ct_es  ON ct_es.character_id  = c.id AND ct_es.lang  = 'es'
+		LEFT JOIN character_translations ct_en  ON ct_en.character_id  = c.id AND ct_en.lang  = 'en'
+		WHERE ra.round_id = $1
+		ORDER BY ra.assigned_at ASC, ra.user_id ASC
+	`, roundID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []RoundAssignment
+	for rows.Next() {
+		var a RoundAssignment
+		if err := rows.Scan(&a.UserID, &a.Character.ID, &a.Character.Name); err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// EndRound ends the room's current round, if any, and returns its ID along with
+// the claims that were still open and got decided by their votes.
+func (s *Storage) EndRound(ctx context.Context, roomID string) (roundID string, claims []ExpiredClaim, err error) {
 	tx, err := s.PG.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
-		return err
+		return "", nil, err
 	}
 	defer func() {
 		if err != nil {
@@ -197,19 +363,66 @@ func (s *Storage) EndRound(ctx context.Context, roomID string) error {
 
 	var cur *string
 	if err = tx.QueryRow(ctx, `SELECT current_round_id FROM rooms WHERE id=$1 FOR UPDATE`, roomID).Scan(&cur); err != nil {
-		return err
+		return "", nil, err
 	}
 	if cur == nil || *cur == "" {
-		return tx.Commit(ctx)
+		return "", nil, tx.Commit(ctx)
+	}
+
+	if claims, err = endRound(ctx, tx, roomID, *cur); err != nil {
+		return "", nil, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return "", nil, err
+	}
+	return *cur, claims, nil
+}
+
+// endRound closes roundID, decides its open claims from their votes as if they
+// had expired, and clears the room's current round. It returns the decided
+// claims. The caller must hold the room row lock.
+func endRound(ctx context.Context, tx pgx.Tx, roomID, roundID string) ([]ExpiredClaim, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT id FROM round_claims
+		WHERE room_id=$1 AND status='open'
+		ORDER BY opened_at ASC
+		FOR UPDATE
+	`, roomID)
+	if err != nil {
+		return nil, err
+	}
+	var open []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		open = append(open, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Score the claims before the round closes so their points still count
+	// towards the round and its game.
+	var claims []ExpiredClaim
+	for _, id := range open {
+		ec, err := timeOutClaim(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		claims = append(claims, ec)
 	}
 
-	if _, err = tx.Exec(ctx, `UPDATE room_rounds SET ended_at=now() WHERE id=$1`, *cur); err != nil {
-		return err
+	if _, err := tx.Exec(ctx, `UPDATE room_rounds SET ended_at=now() WHERE id=$1`, roundID); err != nil {
+		return nil, err
 	}
-	if _, err = tx.Exec(ctx, `UPDATE rooms SET current_round_id=NULL WHERE id=$1`, roomID); err != nil {
-		return err
+	if _, err := tx.Exec(ctx, `UPDATE rooms SET current_round_id=NULL WHERE id=$1`, roomID); err != nil {
+		return nil, err
 	}
-	return tx.Commit(ctx)
+	return claims, nil
 }
 
 func (s *Storage) TouchUser(ctx context.Context, userID string) {
@@ -0,0 +1,192 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/coder/websocket"
+
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/domain"
+)
+
+// Backplane shares room events, sequence numbers and presence between server
+// instances. Each Hub owns one; hubs whose backplanes share state behave like
+// separate instances of the same deployment.
+type Backplane interface {
+	InstanceID() string
+
+	// Publish relays ev to the room's subscribers on other instances.
+	Publish(ctx context.Context, room string, ev BusEvent) error
+	// Subscribe delivers events published for room by other instances to fn
+	// until the returned function is called.
+	Subscribe(room string, fn func(BusEvent)) func()
+
+	NextSeq(ctx context.Context, room string) (int64, error)
+	// Epoch returns the room's shared event epoch, installing candidate if the
+	// room has none yet.
+	Epoch(ctx context.Context, room, candidate string) (string, error)
+
+	SetPresence(ctx context.Context, room, userID string, online bool) error
+	// Presence returns the users connected to the room on any instance.
+	Presence(ctx context.Context, room string) ([]string, error)
+}
+
+// BusEvent is a room message relayed between server instances.
+type BusEvent struct {
+	Origin string          `json:"origin"`
+	Seq    int64           `json:"seq"`
+	Target string          `json:"target,omitempty"` // empty for room-wide events
+	Msg    json.RawMessage `json:"msg"`
+
+	// Evict asks the instance holding Target's socket to send Msg and then
+	// disconnect it with close code Evict.
+	Evict websocket.StatusCode `json:"evict,omitempty"`
+}
+
+func newInstanceID() string {
+	id, err := domain.NewRoomCode(12)
+	if err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return id
+}
+
+// MemoryBus is the shared state behind in-memory backplanes. Backplanes created
+// on the same bus see each other's events, so several hubs in one process can
+// stand in for several server instances.
+type MemoryBus struct {
+	mu       sync.Mutex
+	nextID   int
+	subs     map[string]map[int]memorySub
+	seq      map[string]int64
+	epoch    map[string]string
+	presence map[string]map[string]time.Time
+}
+
+type memorySub struct {
+	instanceID string
+	fn         func(BusEvent)
+}
+
+func NewMemoryBus() *MemoryBus {
+	return &MemoryBus{
+		subs:     map[string]map[int]memorySub{},
+		seq:      map[string]int64{},
+		epoch:    map[string]string{},
+		presence: map[string]map[string]time.Time{},
+	}
+}
+
+// MemoryBackplane is a Backplane kept in process memory. It is the default for
+// a single instance and lets tests run several instances without Redis.
+type MemoryBackplane struct {
+	bus        *MemoryBus
+	instanceID string
+}
+
+// NewMemoryBackplane joins bus as a new instance. A nil bus gets a private one.
+func NewMemoryBackplane(bus *MemoryBus) *MemoryBackplane {
+	if bus == nil {
+		bus = NewMemoryBus()
+	}
+	return &MemoryBackplane{bus: bus, instanceID: newInstanceID()}
+}
+
+func (b *MemoryBackplane) InstanceID() string { return b.instanceID }
+
+func (b *MemoryBackplane) Publish(ctx context.Context, room string, ev BusEvent) error {
+	ev.Origin = b.instanceID
+
+	b.bus.mu.Lock()
+	fns := make([]func(BusEvent), 0, len(b.bus.subs[room]))
+	for _, s := range b.bus.subs[room] {
+		if s.instanceID != b.instanceID {
+			fns = append(fns, s.fn)
+		}
+	}
+	b.bus.mu.Unlock()
+
+	for _, fn := range fns {
+		fn(ev)
+	}
+	return nil
+}
+
+func (b *MemoryBackplane) Subscribe(room string, fn func(BusEvent)) func() {
+	b.bus.mu.Lock()
+	defer b.bus.mu.Unlock()
+
+	id := b.bus.nextID
+	b.bus.nextID++
+	if b.bus.subs[room] == nil {
+		b.bus.subs[room] = map[int]memorySub{}
+	}
+	b.bus.subs[room][id] = memorySub{instanceID: b.instanceID, fn: fn}
+
+	return func() {
+		b.bus.mu.Lock()
+		defer b.bus.mu.Unlock()
+		delete(b.bus.subs[room], id)
+		if len(b.bus.subs[room]) == 0 {
+			// No instance holds the room any more: forget it so the bus does
+			// not grow with every room ever touched. A room created again
+			// gets a new epoch, which makes old clients resync.
+			delete(b.bus.subs, room)
+			delete(b.bus.seq, room)
+			delete(b.bus.epoch, room)
+			delete(b.bus.presence, room)
+		}
+	}
+}
+
+func (b *MemoryBackplane) NextSeq(ctx context.Context, room string) (int64, error) {
+	b.bus.mu.Lock()
+	defer b.bus.mu.Unlock()
+	b.bus.seq[room]++
+	return b.bus.seq[room], nil
+}
+
+func (b *MemoryBackplane) Epoch(ctx context.Context, room, candidate string) (string, error) {
+	b.bus.mu.Lock()
+	defer b.bus.mu.Unlock()
+	if epoch, ok := b.bus.epoch[room]; ok {
+		return epoch, nil
+	}
+	b.bus.epoch[room] = candidate
+	return candidate, nil
+}
+
+func (b *MemoryBackplane) SetPresence(ctx context.Context, room, userID string, online bool) error {
+	b.bus.mu.Lock()
+	defer b.bus.mu.Unlock()
+
+	if !online {
+		delete(b.bus.presence[room], userID)
+		if len(b.bus.presence[room]) == 0 {
+			delete(b.bus.presence, room)
+		}
+		return nil
+	}
+	if b.bus.presence[room] == nil {
+		b.bus.presence[room] = map[string]time.Time{}
+	}
+	b.bus.presence[room][userID] = time.Now()
+	return nil
+}
+
+func (b *MemoryBackplane) Presence(ctx context.Context, room string) ([]string, error) {
+	b.bus.mu.Lock()
+	defer b.bus.mu.Unlock()
+
+	cutoff := time.Now().Add(-presenceTTL)
+	ids := make([]string, 0, len(b.bus.presence[room]))
+	for uid, seen := range b.bus.presence[room] {
+		if seen.After(cutoff) {
+			ids = append(ids, uid)
+		}
+	}
+	return ids, nil
+}
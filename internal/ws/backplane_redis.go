@@ -0,0 +1,111 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// presenceTTL is how long a presence entry survives without being refreshed,
+	// so users connected to a crashed instance eventually show as offline.
+	presenceTTL = 60 * time.Second
+	// roomKeyTTL bounds how long sequence/epoch keys outlive an idle room.
+	roomKeyTTL = 24 * time.Hour
+)
+
+// RedisBackplane fans room events out to every server instance through Redis
+// pub/sub and tracks which users are connected to any instance.
+type RedisBackplane struct {
+	rdb        *redis.Client
+	instanceID string
+}
+
+func NewRedisBackplane(rdb *redis.Client) *RedisBackplane {
+	return &RedisBackplane{rdb: rdb, instanceID: newInstanceID()}
+}
+
+func (b *RedisBackplane) InstanceID() string { return b.instanceID }
+
+func (b *RedisBackplane) key(room, suffix string) string {
+	return "gw:room:" + room + ":" + suffix
+}
+
+func (b *RedisBackplane) Publish(ctx context.Context, room string, ev BusEvent) error {
+	ev.Origin = b.instanceID
+	raw, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	return b.rdb.Publish(ctx, b.key(room, "events"), raw).Err()
+}
+
+func (b *RedisBackplane) Subscribe(room string, fn func(BusEvent)) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	ps := b.rdb.Subscribe(ctx, b.key(room, "events"))
+
+	go func() {
+		defer ps.Close()
+		ch := ps.Channel()
+		for {
+			select {
+			case m, ok := <-ch:
+				if !ok {
+					return
+				}
+				var ev BusEvent
+				if err := json.Unmarshal([]byte(m.Payload), &ev); err != nil {
+					log.Warn().Str("room", room).Err(err).Msg("backplane: dropping malformed event")
+					continue
+				}
+				if ev.Origin == b.instanceID {
+					continue
+				}
+				fn(ev)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return cancel
+}
+
+func (b *RedisBackplane) NextSeq(ctx context.Context, room string) (int64, error) {
+	key := b.key(room, "seq")
+	seq, err := b.rdb.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	b.rdb.Expire(ctx, key, roomKeyTTL)
+	return seq, nil
+}
+
+func (b *RedisBackplane) Epoch(ctx context.Context, room, candidate string) (string, error) {
+	key := b.key(room, "epoch")
+	if err := b.rdb.SetNX(ctx, key, candidate, roomKeyTTL).Err(); err != nil {
+		return "", err
+	}
+	b.rdb.Expire(ctx, key, roomKeyTTL)
+	return b.rdb.Get(ctx, key).Result()
+}
+
+func (b *RedisBackplane) SetPresence(ctx context.Context, room, userID string, online bool) error {
+	key := b.key(room, "presence")
+	if !online {
+		return b.rdb.ZRem(ctx, key, userID).Err()
+	}
+	if err := b.rdb.ZAdd(ctx, key, redis.Z{Score: float64(time.Now().Unix()), Member: userID}).Err(); err != nil {
+		return err
+	}
+	return b.rdb.Expire(ctx, key, roomKeyTTL).Err()
+}
+
+func (b *RedisBackplane) Presence(ctx context.Context, room string) ([]string, error) {
+	min := strconv.FormatInt(time.Now().Add(-presenceTTL).Unix(), 10)
+	return b.rdb.ZRangeByScore(ctx, b.key(room, "presence"), &redis.ZRangeBy{Min: min, Max: "+inf"}).Result()
+}
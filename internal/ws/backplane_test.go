@@ -0,0 +1,144 @@
+package ws
+
+import (
+	"sort"
+	"sync"
+	"testing"
+)
+
+type fakeConn struct {
+	userID string
+
+	mu     sync.Mutex
+	sent   []map[string]any
+	closed bool
+}
+
+func (c *fakeConn) Send(v any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if m, ok := v.(map[string]any); ok {
+		c.sent = append(c.sent, m)
+	}
+	return nil
+}
+
+func (c *fakeConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	return nil
+}
+
+func (c *fakeConn) UserID() string      { return c.userID }
+func (c *fakeConn) Role() string        { return "player" }
+func (c *fakeConn) DisplayName() string { return c.userID }
+
+func (c *fakeConn) types() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]string, 0, len(c.sent))
+	for _, m := range c.sent {
+		out = append(out, m["type"].(string))
+	}
+	return out
+}
+
+// newInstances returns two hubs sharing bus, standing in for two servers.
+func newInstances(bus *MemoryBus) (*Hub, *Hub) {
+	a, b := NewHub(), NewHub()
+	a.UseBackplane(NewMemoryBackplane(bus))
+	b.UseBackplane(NewMemoryBackplane(bus))
+	return a, b
+}
+
+func TestMemoryBusFanOut(t *testing.T) {
+	a, b := newInstances(NewMemoryBus())
+	alice, bob := &fakeConn{userID: "alice"}, &fakeConn{userID: "bob"}
+	a.GetRoom("ABCD").Register(alice)
+	b.GetRoom("ABCD").Register(bob)
+
+	a.GetRoom("ABCD").Broadcast(map[string]any{"type": "test:broadcast"})
+	a.GetRoom("ABCD").SendTo("bob", map[string]any{"type": "test:private"})
+
+	if got := bob.types(); len(got) != 2 || got[0] != "test:broadcast" || got[1] != "test:private" {
+		t.Fatalf("bob got %v, want broadcast then private message", got)
+	}
+	if got := alice.types(); len(got) != 1 || got[0] != "test:broadcast" {
+		t.Fatalf("alice got %v, want only the broadcast", got)
+	}
+
+	// Both instances agree on the sequence and keep the private event, so bob
+	// can resume through either of them.
+	epochA, seqA := a.GetRoom("ABCD").Cursor()
+	epochB, seqB := b.GetRoom("ABCD").Cursor()
+	if epochA != epochB || seqA != seqB {
+		t.Fatalf("cursors differ: %s/%d vs %s/%d", epochA, seqA, epochB, seqB)
+	}
+	for _, h := range []*Hub{a, b} {
+		msgs, ok := h.GetRoom("ABCD").EventsSince(epochA, 0, "bob")
+		if !ok || len(msgs) != 2 {
+			t.Fatalf("EventsSince = %d events, ok=%v; want 2, true", len(msgs), ok)
+		}
+	}
+}
+
+func TestMemoryBusPresence(t *testing.T) {
+	a, b := newInstances(NewMemoryBus())
+	alice, bob := &fakeConn{userID: "alice"}, &fakeConn{userID: "bob"}
+	a.GetRoom("ABCD").Register(alice)
+	b.GetRoom("ABCD").Register(bob)
+
+	for _, h := range []*Hub{a, b} {
+		got := h.GetRoom("ABCD").ConnectedUserIDs()
+		sort.Strings(got)
+		if len(got) != 2 || got[0] != "alice" || got[1] != "bob" {
+			t.Fatalf("ConnectedUserIDs = %v, want [alice bob]", got)
+		}
+	}
+
+	b.GetRoom("ABCD").Unregister(bob)
+	if got := a.GetRoom("ABCD").ConnectedUserIDs(); len(got) != 1 || got[0] != "alice" {
+		t.Fatalf("ConnectedUserIDs after bob left = %v, want [alice]", got)
+	}
+}
+
+func TestMemoryBusEvict(t *testing.T) {
+	a, b := newInstances(NewMemoryBus())
+	bob := &fakeConn{userID: "bob"}
+	a.GetRoom("ABCD")
+	b.GetRoom("ABCD").Register(bob)
+
+	a.GetRoom("ABCD").Evict("bob", map[string]any{"type": "room:kicked"}, StatusKicked)
+
+	if !bob.closed {
+		t.Fatal("bob's connection on the other instance was not closed")
+	}
+	if got := bob.types(); len(got) != 1 || got[0] != "room:kicked" {
+		t.Fatalf("bob got %v, want room:kicked", got)
+	}
+	if got := a.GetRoom("ABCD").ConnectedUserIDs(); len(got) != 0 {
+		t.Fatalf("ConnectedUserIDs after eviction = %v, want none", got)
+	}
+}
+
+func TestMemoryBusForgetsAbandonedRooms(t *testing.T) {
+	bus := NewMemoryBus()
+	a, b := newInstances(bus)
+	alice := &fakeConn{userID: "alice"}
+	a.GetRoom("ABCD").Register(alice)
+	a.GetRoom("ABCD").Broadcast(map[string]any{"type": "test:broadcast"})
+	b.GetRoom("ABCD")
+
+	a.GetRoom("ABCD").Unregister(alice)
+	a.DeleteRoom("ABCD")
+	if _, ok := bus.epoch["ABCD"]; !ok {
+		t.Fatal("room state dropped while another instance still holds the room")
+	}
+
+	b.DeleteRoom("ABCD")
+	if len(bus.subs) != 0 || len(bus.seq) != 0 || len(bus.epoch) != 0 || len(bus.presence) != 0 {
+		t.Fatalf("bus still holds room state: subs=%d seq=%d epoch=%d presence=%d",
+			len(bus.subs), len(bus.seq), len(bus.epoch), len(bus.presence))
+	}
+}
@@ -0,0 +1,60 @@
+package ws
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/storage"
+)
+
+type ClaimResolverConfig struct {
+	Tick  time.Duration // 2s
+	Batch int           // max claims resolved per tick
+}
+
+// StartClaimResolver periodically times out expired claims and broadcasts the
+// result to the rooms connected to this instance.
+func StartClaimResolver(h *Hub, store *storage.Storage, cfg ClaimResolverConfig) func() {
+	if cfg.Batch <= 0 {
+		cfg.Batch = 50
+	}
+	stop := make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(cfg.Tick)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				resolved, err := store.ResolveExpiredClaims(ctx, cfg.Batch)
+				cancel()
+				if err != nil {
+					log.Error().Err(err).Msg("claim resolver: failed to resolve expired claims")
+					continue
+				}
+
+				for _, rc := range resolved {
+					log.Info().
+						Str("room", rc.RoomCode).
+						Str("claim", rc.Claim.ID).
+						Str("status", rc.Claim.Status).
+						Msg("claim resolver: claim timed out")
+
+					if room, ok := h.EventTarget(rc.RoomCode); ok {
+						ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+						publishClaimResolution(ctx, store, room, rc.Claim, rc.Tally, rc.Deltas)
+						cancel()
+					}
+				}
+
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return func() { close(stop) }
+}
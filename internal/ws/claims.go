@@ -0,0 +1,61 @@
+package ws
+
+import (
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/storage"
+)
+
+// eligibleVoters counts the connected users who may vote on a claim. Spectators
+// never vote.
+func eligibleVoters(room *RoomHub, claimantUserID string) int {
+	spectators := map[string]bool{}
+	for _, uid := range room.ConnectedWithRole("spectator") {
+		spectators[uid] = true
+	}
+
+	n := 0
+	for _, uid := range room.ConnectedUserIDs() {
+		if uid != claimantUserID && !spectators[uid] {
+			n++
+		}
+	}
+	return n
+}
+
+func claimOpenedMsg(c *storage.Claim, eligible int) map[string]any {
+	return map[string]any{
+		"type": "claim:opened",
+		"payload": map[string]any{
+			"claimId":        c.ID,
+			"roundId":        c.RoundID,
+			"claimantUserId": c.ClaimantUserID,
+			"endsAt":         c.EndsAt,
+			"eligibleVoters": eligible,
+		},
+	}
+}
+
+func claimVoteCastMsg(c *storage.Claim, voterUserID, vote string, t storage.ClaimTally) map[string]any {
+	return map[string]any{
+		"type": "claim:vote_cast",
+		"payload": map[string]any{
+			"claimId":     c.ID,
+			"voterUserId": voterUserID,
+			"vote":        vote,
+			"tally":       t,
+		},
+	}
+}
+
+func claimResolvedMsg(c *storage.Claim, t storage.ClaimTally) map[string]any {
+	return map[string]any{
+		"type": "claim:resolved",
+		"payload": map[string]any{
+			"claimId":        c.ID,
+			"roundId":        c.RoundID,
+			"claimantUserId": c.ClaimantUserID,
+			"status":         c.Status,
+			"resolvedBy":     c.ResolvedBy,
+			"tally":          t,
+		},
+	}
+}
@@ -11,14 +11,22 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// Close codes sent to clients removed from a room by its host.
+const (
+	StatusKicked websocket.StatusCode = 4001
+	StatusBanned websocket.StatusCode = 4003
+)
+
 type WSConn struct {
 	c      *websocket.Conn
 	ctx    context.Context
 	cancel context.CancelFunc
 
 	userID string
-	role   string
-	name   string
+
+	idMu sync.Mutex
+	role string
+	name string
 
 	sendCh chan []byte
 	once   sync.Once
@@ -52,9 +60,39 @@ func (w *WSConn) Close() error {
 	return nil
 }
 
-func (w *WSConn) UserID() string      { return w.userID }
-func (w *WSConn) Role() string        { return w.role }
-func (w *WSConn) DisplayName() string { return w.name }
+// Evict writes msg straight to the socket, bypassing the send queue, then closes
+// the connection with the given close code.
+func (w *WSConn) Evict(msg any, code websocket.StatusCode, reason string) {
+	writeCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	if err := w.c.Write(writeCtx, websocket.MessageText, Marshal(msg)); err != nil {
+		log.Warn().Str("user", w.userID).Err(err).Msg("ws: failed to write eviction message")
+	}
+	cancel()
+
+	_ = w.c.Close(code, reason)
+	_ = w.Close()
+}
+
+func (w *WSConn) UserID() string { return w.userID }
+
+func (w *WSConn) Role() string {
+	w.idMu.Lock()
+	defer w.idMu.Unlock()
+	return w.role
+}
+
+func (w *WSConn) DisplayName() string {
+	w.idMu.Lock()
+	defer w.idMu.Unlock()
+	return w.name
+}
+
+func (w *WSConn) setIdentity(role, name string) {
+	w.idMu.Lock()
+	defer w.idMu.Unlock()
+	w.role = role
+	w.name = name
+}
 
 func (w *WSConn) Send(v any) error {
 	b, err := json.Marshal(v)
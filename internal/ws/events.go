@@ -0,0 +1,234 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/domain"
+)
+
+// eventBufferSize is how many recent room events are kept for replay on resume.
+const eventBufferSize = 256
+
+type roomEvent struct {
+	seq    int64
+	target string // empty for room-wide events
+	msg    map[string]any
+}
+
+func newEpoch() string {
+	epoch, err := domain.NewRoomCode(8)
+	if err != nil {
+		return "00000000"
+	}
+	return epoch
+}
+
+// attach connects the room to the backplane: it adopts the room's shared epoch
+// and starts receiving events published by other instances.
+func (r *RoomHub) attach(bp Backplane) {
+	r.bp = bp
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	epoch, err := bp.Epoch(ctx, r.code, r.epoch)
+	cancel()
+	if err != nil {
+		log.Warn().Str("room", r.code).Err(err).Msg("ws: failed to read room epoch from backplane")
+	} else {
+		r.epoch = epoch
+	}
+
+	r.unsubscribe = bp.Subscribe(r.code, r.deliver)
+}
+
+func (r *RoomHub) detach() {
+	if r.unsubscribe != nil {
+		r.unsubscribe()
+		r.unsubscribe = nil
+	}
+}
+
+func (r *RoomHub) nextSeq() int64 {
+	if r.bp != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		seq, err := r.bp.NextSeq(ctx, r.code)
+		cancel()
+		if err == nil {
+			return seq
+		}
+		log.Warn().Str("room", r.code).Err(err).Msg("ws: failed to allocate event seq from backplane")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seq++
+	return r.seq
+}
+
+// stamp assigns the next sequence number to msg and appends it to the replay
+// buffer. Messages that are not JSON objects are passed through unsequenced.
+func (r *RoomHub) stamp(target string, msg any) (any, int64) {
+	m, ok := msg.(map[string]any)
+	if !ok {
+		return msg, 0
+	}
+
+	seq := r.nextSeq()
+	stamped := make(map[string]any, len(m)+1)
+	for k, v := range m {
+		stamped[k] = v
+	}
+	stamped["seq"] = seq
+
+	r.mu.Lock()
+	r.recordLocked(roomEvent{seq: seq, target: target, msg: stamped})
+	r.mu.Unlock()
+	return stamped, seq
+}
+
+// recordLocked inserts ev into the replay buffer, keeping it ordered by seq.
+// r.mu must be held.
+func (r *RoomHub) recordLocked(ev roomEvent) {
+	if ev.seq > r.seq {
+		r.seq = ev.seq
+	}
+
+	i := len(r.events)
+	for i > 0 && r.events[i-1].seq > ev.seq {
+		i--
+	}
+	r.events = append(r.events, roomEvent{})
+	copy(r.events[i+1:], r.events[i:])
+	r.events[i] = ev
+
+	if len(r.events) > eventBufferSize {
+		r.events = r.events[len(r.events)-eventBufferSize:]
+	}
+}
+
+// publish relays a locally originated event to the other instances.
+func (r *RoomHub) publish(seq int64, target string, msg any) {
+	if r.bp == nil {
+		return
+	}
+	raw, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := r.bp.Publish(ctx, r.code, BusEvent{Seq: seq, Target: target, Msg: raw}); err != nil {
+		log.Warn().Str("room", r.code).Err(err).Msg("ws: failed to publish event to backplane")
+	}
+}
+
+// deliver hands an event published by another instance to the local sockets.
+func (r *RoomHub) deliver(ev BusEvent) {
+	var msg map[string]any
+	if err := json.Unmarshal(ev.Msg, &msg); err != nil {
+		return
+	}
+
+	if ev.Evict != 0 {
+		r.evictLocal(ev.Target, msg, ev.Evict)
+		return
+	}
+
+	r.mu.Lock()
+	if ev.Seq > 0 {
+		r.recordLocked(roomEvent{seq: ev.Seq, target: ev.Target, msg: msg})
+	}
+	var conns []Conn
+	if ev.Target != "" {
+		if c, ok := r.conns[ev.Target]; ok {
+			conns = append(conns, c)
+		}
+	} else {
+		for _, c := range r.conns {
+			conns = append(conns, c)
+		}
+	}
+	r.mu.Unlock()
+
+	for _, c := range conns {
+		_ = c.Send(msg)
+	}
+}
+
+func (r *RoomHub) setPresence(userID string, online bool) {
+	if r.bp == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := r.bp.SetPresence(ctx, r.code, userID, online); err != nil {
+		log.Warn().Str("room", r.code).Str("user", userID).Err(err).Msg("ws: failed to update presence")
+	}
+}
+
+// RefreshPresence re-announces every local connection so presence entries do
+// not expire while users stay connected.
+func (r *RoomHub) RefreshPresence() {
+	if r.bp == nil {
+		return
+	}
+	r.mu.Lock()
+	ids := make([]string, 0, len(r.conns))
+	for uid := range r.conns {
+		ids = append(ids, uid)
+	}
+	r.mu.Unlock()
+
+	for _, uid := range ids {
+		r.setPresence(uid, true)
+	}
+}
+
+// Cursor returns the room's event epoch and the last sequence number issued.
+func (r *RoomHub) Cursor() (epoch string, seq int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.epoch, r.seq
+}
+
+// EventsSince returns the events userID may see that were issued after lastSeq.
+// ok is false when the gap can no longer be filled from the buffer, including
+// when an event after lastSeq never reached this instance, and the client needs
+// a full resync instead.
+func (r *RoomHub) EventsSince(epoch string, lastSeq int64, userID string) (msgs []map[string]any, ok bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if epoch != r.epoch || lastSeq > r.seq {
+		return nil, false
+	}
+	if lastSeq == r.seq {
+		return nil, true
+	}
+	if len(r.events) == 0 || r.events[0].seq > lastSeq+1 {
+		return nil, false
+	}
+
+	next := lastSeq + 1
+	for _, ev := range r.events {
+		if ev.seq <= lastSeq {
+			continue
+		}
+		if ev.seq != next {
+			return nil, false
+		}
+		next++
+		if ev.target != "" && ev.target != userID {
+			continue
+		}
+		msgs = append(msgs, ev.msg)
+	}
+	if next != r.seq+1 {
+		return nil, false
+	}
+	return msgs, true
+}
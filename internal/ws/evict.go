@@ -0,0 +1,48 @@
+package ws
+
+import (
+	"context"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/rs/zerolog/log"
+)
+
+// Evict removes userID's connection from the room on whichever instance holds
+// it, sending msg before closing the socket with code.
+func (r *RoomHub) Evict(userID string, msg map[string]any, code websocket.StatusCode) {
+	if r.evictLocal(userID, msg, code) || r.bp == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	ev := BusEvent{Target: userID, Msg: Marshal(msg), Evict: code}
+	if err := r.bp.Publish(ctx, r.code, ev); err != nil {
+		log.Warn().Str("room", r.code).Str("user", userID).Err(err).Msg("ws: failed to publish eviction")
+	}
+}
+
+// evictLocal disconnects userID if connected to this instance and reports
+// whether it was.
+func (r *RoomHub) evictLocal(userID string, msg map[string]any, code websocket.StatusCode) bool {
+	r.mu.Lock()
+	c, ok := r.conns[userID]
+	if ok {
+		delete(r.conns, userID)
+		delete(r.connectedSince, userID)
+	}
+	r.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	r.setPresence(userID, false)
+	if wc, ok := c.(*WSConn); ok {
+		wc.Evict(msg, code, "removed from room")
+	} else {
+		_ = c.Send(msg)
+		_ = c.Close()
+	}
+	return true
+}
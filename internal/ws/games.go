@@ -0,0 +1,59 @@
+package ws
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/storage"
+)
+
+func gameStartedMsg(g *storage.Game) map[string]any {
+	return map[string]any{
+		"type":    "game:started",
+		"payload": map[string]any{"game": g},
+	}
+}
+
+// publishGameEnded announces the end of g with its final podium. reason is
+// "host", "rounds" or "score".
+func publishGameEnded(ctx context.Context, store *storage.Storage, room *RoomHub, g *storage.Game, reason string) {
+	payload := map[string]any{
+		"game":   g,
+		"reason": reason,
+	}
+
+	podium, err := store.GamePodium(ctx, g.ID)
+	if err != nil {
+		log.Error().Str("room", room.code).Str("game", g.ID).Err(err).Msg("ws: failed to load game podium")
+	} else {
+		payload["podium"] = podium
+	}
+
+	room.Broadcast(map[string]any{
+		"type":    "game:ended",
+		"payload": payload,
+	})
+}
+
+// completeGameIfDone ends the room's game after a round if it reached its target
+// and announces the podium.
+func completeGameIfDone(ctx context.Context, store *storage.Storage, room *RoomHub, roomID string) {
+	g, err := store.CompleteGameIfDone(ctx, roomID)
+	if err != nil {
+		log.Error().Str("room", room.code).Err(err).Msg("ws: failed to check game completion")
+		return
+	}
+	if g == nil {
+		return
+	}
+	publishGameEnded(ctx, store, room, g, gameEndReason(g))
+}
+
+// gameEndReason tells which target ended a game CompleteGameIfDone completed.
+func gameEndReason(g *storage.Game) string {
+	if g.TargetRounds != nil && g.RoundsPlayed >= *g.TargetRounds {
+		return "rounds"
+	}
+	return "score"
+}
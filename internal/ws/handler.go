@@ -9,6 +9,7 @@ import (
 	"github.com/coder/websocket"
 	"github.com/rs/zerolog/log"
 
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/apierr"
 	"github.com/JsotoSoftware/guess-who-game-backend/internal/auth"
 	"github.com/JsotoSoftware/guess-who-game-backend/internal/storage"
 )
@@ -17,14 +18,47 @@ type Handler struct {
 	Hub    *Hub
 	Store  *storage.Storage
 	Tokens *auth.TokenMaker
+
+	router *Router
 }
 
 func NewHandler(hub *Hub, store *storage.Storage, tokens *auth.TokenMaker) *Handler {
-	return &Handler{
+	h := &Handler{
 		Hub:    hub,
 		Store:  store,
 		Tokens: tokens,
+		router: NewRouter(),
 	}
+	h.routes()
+	return h
+}
+
+func (h *Handler) routes() {
+	r := h.router
+	r.Use(rateLimit(20, 40))
+
+	r.Register("room:join", h.handleJoin)
+	r.Register("room:resume", h.handleResume)
+	r.Register("client:ping", h.handlePing)
+
+	r.Register("host:start_round", h.requireHost(h.handleStartRound))
+	r.Register("host:end_round", h.requireHost(h.handleEndRound))
+	r.Register("host:start_game", h.requireHost(h.handleStartGame))
+	r.Register("host:end_game", h.requireHost(h.handleEndGame))
+	r.Register("host:transfer", h.requireHost(h.handleTransferHost))
+	r.Register("host:kick", h.requireHost(h.handleKick))
+	r.Register("host:ban", h.requireHost(h.handleBan))
+	r.Register("host:unban", h.requireHost(h.handleUnban))
+
+	r.Register("host:score_add", h.requireHost(h.handleScoreAdd))
+	r.Register("host:score_undo", h.requireHost(h.handleScoreUndo))
+	r.Register("host:reset_scores", h.requireHost(h.handleResetScores))
+	r.Register("host:reset_character_pool", h.requireHost(h.handleResetCharacterPool))
+	r.Register("host:update_scoring", h.requireHost(h.handleUpdateScoring))
+	r.Register("host:update_settings", h.requireHost(h.handleUpdateSettings))
+
+	r.Register("player:claim", requireJoined(h.handleClaim))
+	r.Register("player:vote", requireJoined(h.handleVote))
 }
 
 type Envelope struct {
@@ -44,31 +78,91 @@ type StartRoundPayload struct {
 	Lang string `json:"lang,omitempty"`
 }
 
+type StartGamePayload struct {
+	Code        string `json:"code"`
+	Rounds      *int   `json:"rounds,omitempty"`      // end after this many rounds
+	TargetScore *int   `json:"targetScore,omitempty"` // end once someone reaches this score
+}
+
 type ScoreAddPayload struct {
 	Code   string `json:"code"`
 	UserID string `json:"userId"`
 	Delta  int    `json:"delta"`
 }
 
+type UpdateScoringPayload struct {
+	Code    string                `json:"code"`
+	Scoring storage.ScoringPolicy `json:"scoring"`
+}
+
+type ResetCharacterPoolPayload struct {
+	Code  string   `json:"code"`
+	Packs []string `json:"packs,omitempty"` // pack slugs; empty resets every pack
+}
+
+type TransferHostPayload struct {
+	Code   string `json:"code"`
+	UserID string `json:"userId"`
+}
+
+type ModeratePayload struct {
+	Code   string `json:"code"`
+	UserID string `json:"userId"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type UpdateSettingsPayload struct {
+	Code     string          `json:"code"`
+	Settings json.RawMessage `json:"settings"` // partial storage.RoomSettings document
+}
+
+type ResumePayload struct {
+	Code    string `json:"code"`
+	Epoch   string `json:"epoch"`
+	LastSeq int64  `json:"lastSeq"`
+}
+
+type ClaimPayload struct {
+	Code string `json:"code"`
+}
+
+type VotePayload struct {
+	Code    string `json:"code"`
+	ClaimID string `json:"claimId"`
+	Vote    string `json:"vote"`
+}
+
 func addRequestID(m map[string]any, id string) {
 	if id != "" {
 		m["requestId"] = id
 	}
 }
 
+func (h *Handler) sendSnapshot(ctx context.Context, s *Session, roomObj *storage.Room) {
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	snap, err := buildSnapshot(dbCtx, h.Store, s.Room, roomObj, s.UserID, s.Role)
+	if err != nil {
+		log.Error().Str("room", roomObj.Code).Str("user", s.UserID).Err(err).Msg("ws: failed to build room snapshot")
+		return
+	}
+	_ = s.Send(snap)
+}
+
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	raw := r.URL.Query().Get("access_token")
 	if raw == "" {
 		raw = r.URL.Query().Get("token")
 	}
 	if raw == "" {
-		http.Error(w, "missing access_token", http.StatusUnauthorized)
+		apierr.WriteHTTP(w, r, apierr.New(apierr.Unauthorized, "missing access_token"))
 		return
 	}
 
 	claims, err := h.Tokens.ParseAccessToken(raw)
 	if err != nil || claims.UserID == "" {
-		http.Error(w, "invalid token", http.StatusUnauthorized)
+		apierr.WriteHTTP(w, r, apierr.New(apierr.Unauthorized, "invalid token"))
 		return
 	}
 
@@ -85,33 +179,16 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	log.Info().Str("user", userID).Msg("ws: connection established")
 
-	readCtx := r.Context()
 	ctx := r.Context()
-
-	type tmpConn struct {
-		c *websocket.Conn
-	}
-
-	read := func() ([]byte, error) {
-		_, b, err := c.Read(readCtx)
-		return b, err
-	}
-
-	var room *RoomHub
-	var wsconn *WSConn
-	var roomCode string
-	var roomID string
-	var role string
-	var displayName string
-	var dbCtx context.Context
-	var cancel context.CancelFunc
+	s := NewSession(userID, rawSender{ctx: ctx, c: c})
+	s.socket = c
 
 	for {
-		b, err := read()
+		_, b, err := c.Read(ctx)
 		if err != nil {
 			log.Info().
 				Str("user", userID).
-				Str("room", roomCode).
+				Str("room", s.RoomCode).
 				Err(err).
 				Msg("ws: read error, closing connection")
 			break
@@ -121,325 +198,30 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 		var env Envelope
 		if err := json.Unmarshal(b, &env); err != nil {
-			log.Warn().Str("user", userID).Str("room", roomCode).Err(err).Str("raw", string(b)).Msg("ws: received invalid JSON")
-			_ = c.Write(ctx, websocket.MessageText, Marshal(map[string]any{
-				"type": "error", "payload": map[string]any{"message": "bad json"},
-			}))
+			log.Warn().Str("user", userID).Str("room", s.RoomCode).Err(err).Str("raw", string(b)).Msg("ws: received invalid JSON")
+			_ = s.Send(errorMsg(apierr.New(apierr.BadJSON, "bad json"), ""))
 			continue
 		}
 
 		log.Info().
 			Str("user", userID).
-			Str("room", roomCode).
+			Str("room", s.RoomCode).
 			Str("type", env.Type).
 			Int("payloadLen", len(env.Payload)).
 			Msg("ws: message received")
 
-		switch env.Type {
-
-		case "room:join":
-			var p JoinPayload
-			if err := json.Unmarshal(env.Payload, &p); err != nil || p.Code == "" || p.DisplayName == "" || (p.Role != "host" && p.Role != "player") {
-				_ = c.Write(ctx, websocket.MessageText, Marshal(map[string]any{
-					"type": "error", "payload": map[string]any{"message": "invalid join payload"},
-				}))
-				continue
-			}
-
-			// Load room from DB
-			dbCtx, cancel = context.WithTimeout(ctx, 3*time.Second)
-			roomObj, err := h.Store.GetRoomByCode(dbCtx, p.Code)
-			cancel()
-			if err != nil {
-				_ = c.Write(ctx, websocket.MessageText, Marshal(map[string]any{
-					"type": "error", "payload": map[string]any{"message": "room not found"},
-				}))
-				continue
-			}
-
-			// Enforce: only the owner can join as host
-			if p.Role == "host" && roomObj.OwnerUserID != userID {
-				_ = c.Write(ctx, websocket.MessageText, Marshal(map[string]any{
-					"type": "error", "payload": map[string]any{"message": "not host"},
-				}))
-				continue
-			}
-
-			dbCtx, cancel = context.WithTimeout(ctx, 3*time.Second)
-			err = h.Store.UpsertRoomMember(dbCtx, roomObj.ID, userID, p.DisplayName, p.Role)
-			_ = h.Store.TouchRoomActivity(dbCtx, roomObj.ID)
-			cancel()
-			if err != nil {
-				_ = c.Write(ctx, websocket.MessageText, Marshal(map[string]any{
-					"type": "error", "payload": map[string]any{"message": "failed to join room"},
-				}))
-				continue
-			}
-
-			roomCode = p.Code
-			roomID = roomObj.ID
-			role = p.Role
-			displayName = p.DisplayName
-
-			wsconn = NewWSConn(c, userID, role, displayName)
-
-			room = h.Hub.GetRoom(roomCode)
-			room.Register(wsconn)
-
-			dbCtx, cancel = context.WithTimeout(ctx, 3*time.Second)
-			members, _ := h.Store.ListRoomMembers(dbCtx, roomID)
-			cancel()
-
-			for _, m := range members {
-				room.UpsertMemberState(MemberState{
-					UserID:      m.UserID,
-					DisplayName: m.DisplayName,
-					Role:        m.Role,
-					Score:       m.Score,
-					Connected:   false,
-				})
-			}
-
-			room.mu.Lock()
-			for uid, ms := range room.members {
-				_, ok := room.conns[uid]
-				ms.Connected = ok
-				room.members[uid] = ms
-			}
-			room.mu.Unlock()
-
-			_ = wsconn.Send(map[string]any{
-				"type": "room:joined",
-				"payload": map[string]any{
-					"code":   roomCode,
-					"roomId": roomID,
-					"userId": userID,
-					"role":   role,
-				},
-			})
-
-			room.BroadcastPresence()
-
-		case "host:start_round":
-			if wsconn == nil || room == nil {
-				m := map[string]any{"type": "error", "payload": map[string]any{"message": "must join first"}}
-				addRequestID(m, env.RequestID)
-				_ = c.Write(ctx, websocket.MessageText, Marshal(m))
-				continue
-			}
-			if role != "host" {
-				m := map[string]any{"type": "error", "payload": map[string]any{"message": "host only"}}
-				addRequestID(m, env.RequestID)
-				_ = wsconn.Send(m)
-				continue
-			}
-
-			var p StartRoundPayload
-			if err := json.Unmarshal(env.Payload, &p); err != nil {
-				m := map[string]any{"type": "error", "payload": map[string]any{"message": "invalid payload: " + err.Error()}}
-				addRequestID(m, env.RequestID)
-				_ = wsconn.Send(m)
-				continue
-			}
-			if p.Code == "" {
-				m := map[string]any{"type": "error", "payload": map[string]any{"message": "code is required"}}
-				addRequestID(m, env.RequestID)
-				_ = wsconn.Send(m)
-				continue
-			}
-			lang := p.Lang
-			if lang == "" {
-				lang = "es"
-			}
-
-			room.mu.Lock()
-			playerIDs := make([]string, 0, len(room.conns))
-			for uid := range room.conns {
-				playerIDs = append(playerIDs, uid)
-			}
-			room.mu.Unlock()
-
-			if len(playerIDs) == 0 {
-				m := map[string]any{"type": "error", "payload": map[string]any{"message": "no players connected"}}
-				addRequestID(m, env.RequestID)
-				_ = wsconn.Send(m)
-				continue
-			}
-
-			dbCtx, cancel = context.WithTimeout(ctx, 8*time.Second)
-			roundID, assigns, err := h.Store.StartRoundAssignCharacters(dbCtx, roomID, lang, playerIDs)
-			cancel()
-			if err != nil {
-				m := map[string]any{"type": "error", "payload": map[string]any{"message": "failed to start round: " + err.Error()}}
-				addRequestID(m, env.RequestID)
-				_ = wsconn.Send(m)
-				continue
-			}
-
-			// Send each player all OTHER players' assignments (they need to guess their own)
-			for _, currentPlayer := range assigns {
-				// Build list of other players' assignments
-				otherAssignments := make([]map[string]any, 0, len(assigns)-1)
-				for _, a := range assigns {
-					if a.UserID != currentPlayer.UserID {
-						otherAssignments = append(otherAssignments, map[string]any{
-							"userId": a.UserID,
-							"character": map[string]any{
-								"id":   a.Character.ID,
-								"name": a.Character.Name,
-							},
-						})
-					}
-				}
-
-				room.SendTo(currentPlayer.UserID, map[string]any{
-					"type": "round:assigned",
-					"payload": map[string]any{
-						"roundId":     roundID,
-						"assignments": otherAssignments,
-					},
-				})
-			}
-
-			m := map[string]any{
-				"type": "host:round_started",
-				"payload": map[string]any{
-					"roundId":     roundID,
-					"playerCount": len(assigns),
-					"lang":        lang,
-				},
-			}
-			addRequestID(m, env.RequestID)
-			_ = wsconn.Send(m)
-
-			room.BroadcastPresence()
-
-		case "host:score_add":
-			if wsconn == nil || room == nil {
-				m := map[string]any{"type": "error", "payload": map[string]any{"message": "must join first"}}
-				addRequestID(m, env.RequestID)
-				_ = c.Write(ctx, websocket.MessageText, Marshal(m))
-				continue
-			}
-			if role != "host" {
-				m := map[string]any{"type": "error", "payload": map[string]any{"message": "host only"}}
-				addRequestID(m, env.RequestID)
-				_ = c.Write(ctx, websocket.MessageText, Marshal(m))
-				continue
-			}
-
-			var p ScoreAddPayload
-			if err := json.Unmarshal(env.Payload, &p); err != nil {
-				m := map[string]any{"type": "error", "payload": map[string]any{"message": "invalid payload: " + err.Error()}}
-				addRequestID(m, env.RequestID)
-				_ = c.Write(ctx, websocket.MessageText, Marshal(m))
-				continue
-			}
-			if p.UserID == "" || p.Delta == 0 {
-				m := map[string]any{"type": "error", "payload": map[string]any{"message": "userId and delta required, delta must be non-zero"}}
-				addRequestID(m, env.RequestID)
-				_ = c.Write(ctx, websocket.MessageText, Marshal(m))
-				continue
-			}
-
-			dbCtx, cancel = context.WithTimeout(ctx, 3*time.Second)
-			if err := h.Store.AddMemberScore(dbCtx, roomID, p.UserID, p.Delta); err != nil {
-				cancel()
-				m := map[string]any{"type": "error", "payload": map[string]any{"message": "score update failed: " + err.Error()}}
-				addRequestID(m, env.RequestID)
-				_ = c.Write(ctx, websocket.MessageText, Marshal(m))
-				continue
-			}
-			members, _ := h.Store.ListRoomMembersWithConnectionHint(dbCtx, roomID)
-			cancel()
-			for _, m := range members {
-				room.UpsertMemberState(MemberState{
-					UserID:      m.UserID,
-					DisplayName: m.DisplayName,
-					Role:        m.Role,
-					Score:       m.Score,
-					Connected:   true,
-				})
-			}
-			room.mu.Lock()
-			for uid := range room.members {
-				_, ok := room.conns[uid]
-				room.members[uid] = MemberState{
-					UserID:      room.members[uid].UserID,
-					DisplayName: room.members[uid].DisplayName,
-					Role:        room.members[uid].Role,
-					Score:       room.members[uid].Score,
-					Connected:   ok,
-				}
-			}
-			room.mu.Unlock()
-
-			m := map[string]any{
-				"type": "host:score_added",
-				"payload": map[string]any{
-					"userId": p.UserID,
-					"delta":  p.Delta,
-				},
-			}
-			addRequestID(m, env.RequestID)
-			_ = wsconn.Send(m)
-
-			room.BroadcastPresence()
-
-		case "host:end_round":
-			if wsconn == nil || room == nil {
-				m := map[string]any{"type": "error", "payload": map[string]any{"message": "must join first"}}
-				addRequestID(m, env.RequestID)
-				_ = c.Write(ctx, websocket.MessageText, Marshal(m))
-				continue
-			}
-			if role != "host" {
-				m := map[string]any{"type": "error", "payload": map[string]any{"message": "host only"}}
-				addRequestID(m, env.RequestID)
-				_ = wsconn.Send(m)
-				continue
-			}
-
-			dbCtx, cancel = context.WithTimeout(ctx, 3*time.Second)
-			err = h.Store.EndRound(dbCtx, roomID)
-			cancel()
-			if err != nil {
-				m := map[string]any{"type": "error", "payload": map[string]any{"message": "failed to end round: " + err.Error()}}
-				addRequestID(m, env.RequestID)
-				_ = wsconn.Send(m)
-				continue
-			}
-
-			m := map[string]any{"type": "host:round_ended"}
-			addRequestID(m, env.RequestID)
-			_ = wsconn.Send(m)
-			room.BroadcastPresence()
-
-		case "client:ping":
-			if wsconn != nil {
-				_ = wsconn.Send(map[string]any{"type": "server:pong", "payload": map[string]any{"ts": time.Now().UnixMilli()}})
-			} else {
-				_ = c.Write(ctx, websocket.MessageText, Marshal(map[string]any{"type": "server:pong"}))
-			}
-
-		default:
-			log.Debug().Str("user", userID).Str("type", env.Type).Msg("ws: unknown message type, ignoring")
-		}
-
-		if wsconn != nil {
-			wsconn.Touch()
-		}
+		h.router.Dispatch(ctx, s, env)
+		s.Touch()
 	}
 
-	if room != nil {
-		room.Unregister(userID)
-		room.SetConnected(userID, false)
-		room.BroadcastPresence()
+	if s.Joined() && s.Room.Unregister(s.Conn) {
+		s.Room.SetConnected(userID, false)
+		s.Room.BroadcastPresence()
 	}
-	if wsconn != nil {
-		_ = wsconn.Close()
+	if s.Conn != nil {
+		_ = s.Conn.Close()
 	}
 
 	c.Close(websocket.StatusNormalClosure, "bye")
-	log.Info().Str("user", userID).Str("room", roomCode).Msg("ws: connection closed")
+	log.Info().Str("user", userID).Str("room", s.RoomCode).Msg("ws: connection closed")
 }
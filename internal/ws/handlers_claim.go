@@ -0,0 +1,65 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/apierr"
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/storage"
+)
+
+func (h *Handler) handleClaim(ctx context.Context, s *Session, env Envelope) error {
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	settings, err := h.Store.GetRoomSettings(dbCtx, s.RoomID)
+	var claim *storage.Claim
+	if err == nil {
+		claim, err = h.Store.OpenClaim(dbCtx, s.RoomID, s.UserID, settings.ClaimTimeout())
+	}
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	s.Room.Broadcast(claimOpenedMsg(claim, eligibleVoters(s.Room, s.UserID)))
+	return nil
+}
+
+func (h *Handler) handleVote(ctx context.Context, s *Session, env Envelope) error {
+	var p VotePayload
+	if err := json.Unmarshal(env.Payload, &p); err != nil || p.ClaimID == "" || (p.Vote != storage.VoteYes && p.Vote != storage.VoteNo) {
+		return apierr.New(apierr.InvalidPayload, "invalid vote payload")
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	claim, tally, err := h.Store.CastClaimVote(dbCtx, s.RoomID, p.ClaimID, s.UserID, p.Vote)
+	cancel()
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return apierr.New(apierr.ClaimNotFound, "claim not found")
+		}
+		return err
+	}
+
+	s.Room.Broadcast(claimVoteCastMsg(claim, s.UserID, p.Vote, tally))
+
+	status, decided := storage.DecideClaim(tally, eligibleVoters(s.Room, claim.ClaimantUserID))
+	if !decided {
+		return nil
+	}
+
+	dbCtx, cancel = context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	resolved, tally, deltas, err := h.Store.ResolveClaim(dbCtx, claim.ID, status, storage.ResolvedByVotes)
+	if err != nil {
+		if err != storage.ErrClaimNotOpen {
+			log.Error().Str("room", s.RoomCode).Str("claim", claim.ID).Err(err).Msg("ws: failed to resolve claim")
+		}
+		return nil
+	}
+	publishClaimResolution(dbCtx, h.Store, s.Room, resolved, tally, deltas)
+	return nil
+}
@@ -0,0 +1,50 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/apierr"
+)
+
+func (h *Handler) handleStartGame(ctx context.Context, s *Session, env Envelope) error {
+	var p StartGamePayload
+	if err := json.Unmarshal(env.Payload, &p); err != nil {
+		return apierr.New(apierr.InvalidPayload, "invalid start game payload")
+	}
+	if (p.Rounds != nil && *p.Rounds <= 0) || (p.TargetScore != nil && *p.TargetScore <= 0) {
+		return apierr.New(apierr.InvalidPayload, "rounds and targetScore must be positive")
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	g, err := h.Store.StartGame(dbCtx, s.RoomID, s.UserID, p.Rounds, p.TargetScore)
+	if err != nil {
+		return err
+	}
+	syncMembers(dbCtx, h.Store, s.Room, s.RoomID)
+
+	s.Room.Broadcast(gameStartedMsg(g))
+	_ = s.Reply(env, "host:game_started", map[string]any{"gameId": g.ID})
+
+	s.Room.BroadcastPresence()
+	return nil
+}
+
+func (h *Handler) handleEndGame(ctx context.Context, s *Session, env Envelope) error {
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	g, roundID, claims, err := h.Store.EndGame(dbCtx, s.RoomID)
+	if err != nil {
+		return err
+	}
+
+	_ = s.Reply(env, "host:game_ended", map[string]any{"gameId": g.ID})
+	if roundID != "" {
+		publishRoundEnded(dbCtx, h.Store, s.Room, roundID, "host", claims)
+	}
+	publishGameEnded(dbCtx, h.Store, s.Room, g, "host")
+	s.Room.BroadcastPresence()
+	return nil
+}
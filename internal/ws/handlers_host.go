@@ -0,0 +1,130 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/apierr"
+)
+
+func (h *Handler) handleTransferHost(ctx context.Context, s *Session, env Envelope) error {
+	var p TransferHostPayload
+	if err := json.Unmarshal(env.Payload, &p); err != nil || p.UserID == "" {
+		return apierr.New(apierr.InvalidPayload, "invalid transfer payload")
+	}
+	if p.UserID == s.UserID {
+		return apierr.New(apierr.InvalidPayload, "already the host")
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	if err := h.Store.TransferRoomOwnership(dbCtx, s.RoomID, s.UserID, p.UserID); err != nil {
+		if err == pgx.ErrNoRows {
+			return apierr.New(apierr.MemberNotFound, "user is not a member of this room")
+		}
+		return err
+	}
+	s.Role = "player"
+
+	_ = s.Reply(env, "host:transferred", map[string]any{
+		"hostUserId": p.UserID,
+	})
+
+	announceHostChange(dbCtx, h.Store, s.Room, s.UserID, p.UserID, "transfer")
+	return nil
+}
+
+func (h *Handler) handleKick(ctx context.Context, s *Session, env Envelope) error {
+	p, err := moderatePayload(s, env)
+	if err != nil {
+		return err
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	if err := h.Store.KickRoomMember(dbCtx, s.RoomID, p.UserID); err != nil {
+		if err == pgx.ErrNoRows {
+			return apierr.New(apierr.MemberNotFound, "user is not a member of this room")
+		}
+		return err
+	}
+
+	h.removeMember(dbCtx, s, p.UserID, "kicked", p.Reason, StatusKicked)
+	return s.Reply(env, "host:kicked", map[string]any{"userId": p.UserID})
+}
+
+func (h *Handler) handleBan(ctx context.Context, s *Session, env Envelope) error {
+	p, err := moderatePayload(s, env)
+	if err != nil {
+		return err
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	if err := h.Store.BanRoomMember(dbCtx, s.RoomID, p.UserID, s.UserID, p.Reason); err != nil {
+		if err == pgx.ErrNoRows {
+			return apierr.New(apierr.NotFound, "user not found")
+		}
+		return err
+	}
+
+	h.removeMember(dbCtx, s, p.UserID, "banned", p.Reason, StatusBanned)
+	return s.Reply(env, "host:banned", map[string]any{"userId": p.UserID})
+}
+
+func (h *Handler) handleUnban(ctx context.Context, s *Session, env Envelope) error {
+	p, err := moderatePayload(s, env)
+	if err != nil {
+		return err
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	if err := h.Store.UnbanRoomMember(dbCtx, s.RoomID, p.UserID); err != nil {
+		if err == pgx.ErrNoRows {
+			return apierr.New(apierr.NotFound, "user is not banned")
+		}
+		return err
+	}
+	return s.Reply(env, "host:unbanned", map[string]any{"userId": p.UserID})
+}
+
+func moderatePayload(s *Session, env Envelope) (ModeratePayload, error) {
+	var p ModeratePayload
+	if err := json.Unmarshal(env.Payload, &p); err != nil || p.UserID == "" {
+		return p, apierr.New(apierr.InvalidPayload, "invalid moderation payload")
+	}
+	if p.UserID == s.UserID {
+		return p, apierr.New(apierr.InvalidPayload, "cannot remove yourself")
+	}
+	return p, nil
+}
+
+// removeMember disconnects a kicked or banned user and tells the rest of the
+// room they are gone.
+func (h *Handler) removeMember(ctx context.Context, s *Session, userID, action, reason string, code websocket.StatusCode) {
+	s.Room.Evict(userID, map[string]any{
+		"type": "room:kicked",
+		"payload": map[string]any{
+			"code":   s.RoomCode,
+			"action": action,
+			"reason": reason,
+		},
+	}, code)
+
+	syncMembers(ctx, h.Store, s.Room, s.RoomID)
+
+	s.Room.Broadcast(map[string]any{
+		"type": "room:member_removed",
+		"payload": map[string]any{
+			"code":   s.RoomCode,
+			"userId": userID,
+			"action": action,
+		},
+	})
+	s.Room.BroadcastPresence()
+}
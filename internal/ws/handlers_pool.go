@@ -0,0 +1,39 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/apierr"
+)
+
+func (h *Handler) handleResetCharacterPool(ctx context.Context, s *Session, env Envelope) error {
+	var p ResetCharacterPoolPayload
+	if len(env.Payload) > 0 {
+		if err := json.Unmarshal(env.Payload, &p); err != nil {
+			return apierr.New(apierr.InvalidPayload, "invalid reset character pool payload")
+		}
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	released, err := h.Store.ResetCharacterPool(dbCtx, s.RoomID, p.Packs)
+	if err != nil {
+		return err
+	}
+	refreshPool(dbCtx, h.Store, s.Room, s.RoomID)
+
+	_ = s.Reply(env, "host:character_pool_reset", map[string]any{"released": released})
+
+	s.Room.Broadcast(map[string]any{
+		"type": "room:character_pool_reset",
+		"payload": map[string]any{
+			"packs":    p.Packs,
+			"released": released,
+			"pool":     s.Room.PoolState(),
+		},
+	})
+	s.Room.BroadcastPresence()
+	return nil
+}
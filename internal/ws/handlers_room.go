@@ -0,0 +1,145 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/apierr"
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/storage"
+)
+
+func (h *Handler) handleJoin(ctx context.Context, s *Session, env Envelope) error {
+	var p JoinPayload
+	if err := json.Unmarshal(env.Payload, &p); err != nil || p.Code == "" || p.DisplayName == "" || (p.Role != "host" && p.Role != "player" && p.Role != "spectator") {
+		return apierr.New(apierr.InvalidPayload, "invalid join payload")
+	}
+
+	// Load room from DB
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	roomObj, err := h.Store.GetRoomByCode(dbCtx, p.Code)
+	cancel()
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return apierr.New(apierr.RoomNotFound, "room not found")
+		}
+		return err
+	}
+
+	// Enforce: only the owner can join as host. The owner always joins as host,
+	// so a player promoted by a host transfer keeps the role when reconnecting.
+	role := p.Role
+	if roomObj.OwnerUserID == s.UserID {
+		role = "host"
+	} else if role == "host" {
+		return apierr.New(apierr.NotHost, "only the room owner can join as host")
+	}
+
+	dbCtx, cancel = context.WithTimeout(ctx, 3*time.Second)
+	err = h.Store.UpsertRoomMember(dbCtx, roomObj.ID, s.UserID, p.DisplayName, role)
+	_ = h.Store.TouchRoomActivity(dbCtx, roomObj.ID)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	h.enterRoom(ctx, s, roomObj, role, p.DisplayName)
+
+	epoch, seq := s.Room.Cursor()
+	_ = s.Reply(env, "room:joined", map[string]any{
+		"code":   s.RoomCode,
+		"roomId": s.RoomID,
+		"userId": s.UserID,
+		"role":   s.Role,
+		"epoch":  epoch,
+		"seq":    seq,
+	})
+
+	s.Room.BroadcastPresence()
+	h.sendSnapshot(ctx, s, roomObj)
+	return nil
+}
+
+func (h *Handler) handleResume(ctx context.Context, s *Session, env Envelope) error {
+	var p ResumePayload
+	if err := json.Unmarshal(env.Payload, &p); err != nil || p.Code == "" {
+		return apierr.New(apierr.InvalidPayload, "invalid resume payload")
+	}
+	if s.Joined() && p.Code != s.RoomCode {
+		return apierr.New(apierr.AlreadyInRoom, "already in another room")
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	roomObj, err := h.Store.GetRoomByCode(dbCtx, p.Code)
+	var member *storage.RoomMember
+	if err == nil {
+		member, err = h.Store.GetRoomMember(dbCtx, roomObj.ID, s.UserID)
+	}
+	cancel()
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return apierr.New(apierr.NotJoined, "cannot resume, join the room first")
+		}
+		return err
+	}
+
+	h.enterRoom(ctx, s, roomObj, member.Role, member.DisplayName)
+
+	missed, ok := s.Room.EventsSince(p.Epoch, p.LastSeq, s.UserID)
+	epoch, seq := s.Room.Cursor()
+
+	_ = s.Reply(env, "room:resumed", map[string]any{
+		"code":     s.RoomCode,
+		"roomId":   s.RoomID,
+		"userId":   s.UserID,
+		"role":     s.Role,
+		"epoch":    epoch,
+		"seq":      seq,
+		"replayed": len(missed),
+		"resync":   !ok,
+	})
+
+	if ok {
+		for _, ev := range missed {
+			_ = s.Send(ev)
+		}
+	} else {
+		// Too far behind to replay: send the full room state instead.
+		h.sendSnapshot(ctx, s, roomObj)
+	}
+
+	s.Room.BroadcastPresence()
+	return nil
+}
+
+// enterRoom registers the session's socket in the room hub and refreshes the
+// room's member state from the database.
+func (h *Handler) enterRoom(ctx context.Context, s *Session, roomObj *storage.Room, role, displayName string) {
+	if s.Joined() && s.RoomCode != roomObj.Code && s.Room.Unregister(s.Conn) {
+		s.Room.SetConnected(s.UserID, false)
+		s.Room.BroadcastPresence()
+	}
+
+	s.RoomCode = roomObj.Code
+	s.RoomID = roomObj.ID
+	s.Role = role
+	s.DisplayName = displayName
+
+	room := h.Hub.GetRoom(s.RoomCode)
+	conn := s.connect(role, displayName)
+	room.Register(conn)
+	s.Attach(room, conn)
+
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	syncMembers(dbCtx, h.Store, room, s.RoomID)
+	cancel()
+}
+
+func (h *Handler) handlePing(ctx context.Context, s *Session, env Envelope) error {
+	if s.Joined() {
+		return s.Reply(env, "server:pong", map[string]any{"ts": time.Now().UnixMilli()})
+	}
+	return s.Reply(env, "server:pong", nil)
+}
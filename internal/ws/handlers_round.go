@@ -0,0 +1,92 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/apierr"
+)
+
+func (h *Handler) handleStartRound(ctx context.Context, s *Session, env Envelope) error {
+	var p StartRoundPayload
+	if err := json.Unmarshal(env.Payload, &p); err != nil {
+		return apierr.New(apierr.InvalidPayload, "invalid start round payload")
+	}
+	if p.Code == "" {
+		return apierr.New(apierr.InvalidPayload, "code is required")
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	settings, err := h.Store.GetRoomSettings(dbCtx, s.RoomID)
+	cancel()
+	if err != nil {
+		return err
+	}
+	lang := p.Lang
+	if lang == "" {
+		lang = settings.Lang
+	}
+
+	playerIDs := s.Room.ConnectedUserIDs()
+	if len(playerIDs) == 0 {
+		return apierr.New(apierr.NoPlayersConnected, "no players connected")
+	}
+
+	dbCtx, cancel = context.WithTimeout(ctx, 8*time.Second)
+	roundID, endsAt, assigns, err := h.Store.StartRoundAssignCharacters(dbCtx, s.RoomID, lang, playerIDs)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	s.Room.Broadcast(roundStartedMsg(roundID, lang, endsAt, assigns))
+	if endsAt != nil {
+		s.Room.Broadcast(roundEndsAtMsg(roundID, *endsAt))
+	}
+
+	// Send each player all OTHER players' assignments (they need to guess their own)
+	for _, currentPlayer := range assigns {
+		s.Room.SendTo(currentPlayer.UserID, roundAssignedMsg(roundID, assigns, currentPlayer.UserID))
+	}
+
+	// A moderating host runs the game and needs to see every character.
+	if !settings.HostPlays {
+		s.Room.SendTo(s.UserID, roundAssignedMsg(roundID, assigns, s.UserID))
+	}
+	if settings.SpectatorsSeeAll {
+		for _, uid := range s.Room.ConnectedWithRole("spectator") {
+			s.Room.SendTo(uid, roundAssignedMsg(roundID, assigns, uid))
+		}
+	}
+
+	_ = s.Reply(env, "host:round_started", map[string]any{
+		"roundId":     roundID,
+		"playerCount": len(assigns),
+		"lang":        lang,
+		"endsAt":      endsAt,
+	})
+
+	dbCtx, cancel = context.WithTimeout(ctx, 3*time.Second)
+	refreshPool(dbCtx, h.Store, s.Room, s.RoomID)
+	cancel()
+	s.Room.BroadcastPresence()
+	return nil
+}
+
+func (h *Handler) handleEndRound(ctx context.Context, s *Session, env Envelope) error {
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	roundID, claims, err := h.Store.EndRound(dbCtx, s.RoomID)
+	if err != nil {
+		return err
+	}
+
+	_ = s.Reply(env, "host:round_ended", nil)
+	if roundID != "" {
+		publishRoundEnded(dbCtx, h.Store, s.Room, roundID, "host", claims)
+		completeGameIfDone(dbCtx, h.Store, s.Room, s.RoomID)
+	}
+	s.Room.BroadcastPresence()
+	return nil
+}
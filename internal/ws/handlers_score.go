@@ -0,0 +1,108 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/apierr"
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/storage"
+)
+
+func (h *Handler) handleScoreAdd(ctx context.Context, s *Session, env Envelope) error {
+	var p ScoreAddPayload
+	if err := json.Unmarshal(env.Payload, &p); err != nil {
+		return apierr.New(apierr.InvalidPayload, "invalid score payload")
+	}
+	if p.UserID == "" || p.Delta == 0 {
+		return apierr.New(apierr.InvalidPayload, "userId and delta required, delta must be non-zero")
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	if _, err := h.Store.AddMemberScore(dbCtx, s.RoomID, p.UserID, p.Delta, s.UserID); err != nil {
+		if err == pgx.ErrNoRows {
+			return apierr.New(apierr.MemberNotFound, "user is not a member of this room")
+		}
+		return err
+	}
+	syncMembers(dbCtx, h.Store, s.Room, s.RoomID)
+
+	s.Room.Broadcast(scoreChangedMsg("", []storage.ScoreDelta{
+		{UserID: p.UserID, Delta: p.Delta, Reason: storage.ReasonManual},
+	}))
+
+	_ = s.Reply(env, "host:score_added", map[string]any{
+		"userId": p.UserID,
+		"delta":  p.Delta,
+	})
+
+	s.Room.BroadcastPresence()
+	return nil
+}
+
+func (h *Handler) handleScoreUndo(ctx context.Context, s *Session, env Envelope) error {
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	undo, err := h.Store.UndoLastScoreEvent(dbCtx, s.RoomID, s.UserID)
+	if err != nil {
+		return err
+	}
+	syncMembers(dbCtx, h.Store, s.Room, s.RoomID)
+
+	s.Room.Broadcast(scoreChangedMsg("", []storage.ScoreDelta{
+		{UserID: undo.UserID, Delta: undo.Delta, Reason: storage.ReasonUndo},
+	}))
+
+	_ = s.Reply(env, "host:score_undone", map[string]any{
+		"event": undo,
+	})
+
+	s.Room.BroadcastPresence()
+	return nil
+}
+
+// handleUpdateScoring replaces the room's scoring policy. It is a shortcut for
+// host:update_settings with only the scoring key.
+func (h *Handler) handleUpdateScoring(ctx context.Context, s *Session, env Envelope) error {
+	var p UpdateScoringPayload
+	if err := json.Unmarshal(env.Payload, &p); err != nil {
+		return apierr.New(apierr.InvalidPayload, "invalid scoring payload")
+	}
+	patch, err := json.Marshal(map[string]any{"scoring": p.Scoring})
+	if err != nil {
+		return err
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	settings, err := h.Store.UpdateRoomSettings(dbCtx, s.RoomID, patch)
+	if err != nil {
+		return err
+	}
+
+	_ = s.Reply(env, "host:scoring_updated", map[string]any{"scoring": settings.Scoring})
+
+	publishSettings(dbCtx, h.Store, s.Room, s.RoomID, settings)
+	return nil
+}
+
+func (h *Handler) handleResetScores(ctx context.Context, s *Session, env Envelope) error {
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	if err := h.Store.ResetScores(dbCtx, s.RoomID, s.UserID); err != nil {
+		return err
+	}
+	syncMembers(dbCtx, h.Store, s.Room, s.RoomID)
+
+	_ = s.Reply(env, "host:scores_reset", nil)
+
+	s.Room.Broadcast(map[string]any{
+		"type":    "room:scores_reset",
+		"payload": map[string]any{"members": s.Room.MemberStates()},
+	})
+	s.Room.BroadcastPresence()
+	return nil
+}
@@ -0,0 +1,28 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/apierr"
+)
+
+func (h *Handler) handleUpdateSettings(ctx context.Context, s *Session, env Envelope) error {
+	var p UpdateSettingsPayload
+	if err := json.Unmarshal(env.Payload, &p); err != nil || len(p.Settings) == 0 {
+		return apierr.New(apierr.InvalidPayload, "invalid settings payload")
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	settings, err := h.Store.UpdateRoomSettings(dbCtx, s.RoomID, p.Settings)
+	if err != nil {
+		return err
+	}
+
+	_ = s.Reply(env, "host:settings_updated", map[string]any{"settings": settings})
+
+	publishSettings(dbCtx, h.Store, s.Room, s.RoomID, settings)
+	return nil
+}
@@ -0,0 +1,129 @@
+package ws
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/storage"
+)
+
+type HostFailoverConfig struct {
+	Grace time.Duration // how long the host may be disconnected before being replaced
+	Tick  time.Duration // 5s
+}
+
+// StartHostFailover promotes the longest-connected player of a room on this
+// instance once the host has been disconnected from every instance for longer
+// than cfg.Grace. Ownership is changed with a conditional update, so when several
+// instances race only one promotion wins.
+func StartHostFailover(h *Hub, store *storage.Storage, cfg HostFailoverConfig) func() {
+	stop := make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(cfg.Tick)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				now := time.Now()
+				for code, r := range h.RoomSnapshot() {
+					host, candidate, ok := r.failoverCandidate(now, cfg.Grace)
+					if !ok {
+						continue
+					}
+
+					ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+					err := store.TransferRoomOwnership(ctx, r.roomID, host, candidate)
+					if err != nil {
+						if err != storage.ErrNotHost && err != storage.ErrCannotHost {
+							log.Error().Str("room", code).Err(err).Msg("host failover: failed to transfer ownership")
+						}
+						// Someone else changed the host or the candidate's role
+						// first; pick up the current roles.
+						syncMembers(ctx, store, r, r.roomID)
+						cancel()
+						continue
+					}
+
+					log.Info().Str("room", code).Str("from", host).Str("to", candidate).Msg("host failover: promoted new host")
+					announceHostChange(ctx, store, r, host, candidate, "failover")
+					cancel()
+				}
+
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return func() { close(stop) }
+}
+
+// failoverCandidate tracks how long the room's host has been away and, once the
+// grace period has passed, returns the host and the local member connected the
+// longest who may host (see storage.CanHost).
+func (r *RoomHub) failoverCandidate(now time.Time, grace time.Duration) (host, candidate string, ok bool) {
+	connected := map[string]bool{}
+	for _, uid := range r.ConnectedUserIDs() {
+		connected[uid] = true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.roomID == "" {
+		return "", "", false
+	}
+	for _, m := range r.members {
+		if m.Role == "host" {
+			host = m.UserID
+			break
+		}
+	}
+	if host == "" || connected[host] {
+		r.hostAwaySince = time.Time{}
+		return "", "", false
+	}
+	if r.hostAwaySince.IsZero() {
+		r.hostAwaySince = now
+		return "", "", false
+	}
+	if now.Sub(r.hostAwaySince) < grace {
+		return "", "", false
+	}
+
+	var since time.Time
+	for uid, t := range r.connectedSince {
+		m, isMember := r.members[uid]
+		if !isMember || !storage.CanHost(m.Role) {
+			continue
+		}
+		if candidate == "" || t.Before(since) {
+			candidate, since = uid, t
+		}
+	}
+	return host, candidate, candidate != ""
+}
+
+// announceHostChange refreshes member roles and tells the room who hosts it now.
+func announceHostChange(ctx context.Context, store *storage.Storage, room *RoomHub, from, to, reason string) {
+	room.mu.Lock()
+	room.hostAwaySince = time.Time{}
+	roomID := room.roomID
+	room.mu.Unlock()
+
+	syncMembers(ctx, store, room, roomID)
+
+	room.Broadcast(map[string]any{
+		"type": "room:host_changed",
+		"payload": map[string]any{
+			"code":               room.code,
+			"previousHostUserId": from,
+			"hostUserId":         to,
+			"reason":             reason,
+		},
+	})
+	room.BroadcastPresence()
+}
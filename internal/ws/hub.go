@@ -1,9 +1,14 @@
 package ws
 
 import (
+	"context"
 	"encoding/json"
 	"sync"
 	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/storage"
 )
 
 type Conn interface {
@@ -17,18 +22,45 @@ type Conn interface {
 type Hub struct {
 	mu    sync.Mutex
 	rooms map[string]*RoomHub
+	bp    Backplane
+
+	// shared is set once the backplane connects this hub to other instances.
+	shared bool
 }
 
 func NewHub() *Hub {
-	return &Hub{rooms: make(map[string]*RoomHub)}
+	return &Hub{rooms: make(map[string]*RoomHub), bp: NewMemoryBackplane(nil)}
+}
+
+// UseBackplane replaces the hub's private in-memory backplane, e.g. with Redis
+// so rooms share events and presence with other server instances. It must be
+// called before any room is created.
+func (h *Hub) UseBackplane(bp Backplane) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.bp = bp
+	h.shared = true
 }
 
 type RoomHub struct {
 	code         string
+	roomID       string
 	mu           sync.Mutex
 	conns        map[string]Conn
 	members      map[string]MemberState
 	lastActivity time.Time
+
+	connectedSince map[string]time.Time
+	hostAwaySince  time.Time
+	hostPlays      bool
+	pool           *storage.RoomPool
+
+	epoch  string
+	seq    int64
+	events []roomEvent
+
+	bp          Backplane
+	unsubscribe func()
 }
 
 type MemberState struct {
@@ -41,10 +73,13 @@ type MemberState struct {
 
 func NewRoomHub(code string) *RoomHub {
 	return &RoomHub{
-		code:         code,
-		conns:        map[string]Conn{},
-		members:      map[string]MemberState{},
-		lastActivity: time.Now(),
+		code:           code,
+		conns:          map[string]Conn{},
+		members:        map[string]MemberState{},
+		lastActivity:   time.Now(),
+		connectedSince: map[string]time.Time{},
+		hostPlays:      true,
+		epoch:          newEpoch(),
 	}
 }
 
@@ -54,11 +89,57 @@ func (h *Hub) GetRoom(code string) *RoomHub {
 	r, ok := h.rooms[code]
 	if !ok {
 		r = NewRoomHub(code)
+		if h.bp != nil {
+			r.attach(h.bp)
+		}
 		h.rooms[code] = r
 	}
 	return r
 }
 
+func (h *Hub) LookupRoom(code string) (*RoomHub, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	r, ok := h.rooms[code]
+	return r, ok
+}
+
+// EventTarget returns the room hub to use for server-originated events (timers,
+// background jobs). A standalone hub can only reach rooms with local
+// connections; a hub sharing its backplane creates the room on demand so the
+// event still fans out to the instances that hold the room's sockets.
+func (h *Hub) EventTarget(code string) (*RoomHub, bool) {
+	h.mu.Lock()
+	shared := h.shared
+	h.mu.Unlock()
+
+	if shared {
+		return h.GetRoom(code), true
+	}
+	return h.LookupRoom(code)
+}
+
+// Presence returns the users connected to room code on any instance, without
+// creating the room on this one.
+func (h *Hub) Presence(ctx context.Context, code string) []string {
+	h.mu.Lock()
+	r, ok := h.rooms[code]
+	bp, shared := h.bp, h.shared
+	h.mu.Unlock()
+
+	if ok {
+		return r.ConnectedUserIDs()
+	}
+	if !shared {
+		return nil
+	}
+	ids, err := bp.Presence(ctx, code)
+	if err != nil {
+		log.Warn().Str("room", code).Err(err).Msg("ws: failed to read presence from backplane")
+	}
+	return ids
+}
+
 func (r *RoomHub) UpsertMemberState(m MemberState) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -75,61 +156,174 @@ func (r *RoomHub) SetConnected(userID string, connected bool) {
 	}
 }
 
-func (r *RoomHub) Register(c Conn) {
+// MemberStates returns the room's members with their connected flag reflecting
+// connections on every instance.
+func (r *RoomHub) MemberStates() []MemberState {
+	connected := map[string]bool{}
+	for _, uid := range r.ConnectedUserIDs() {
+		connected[uid] = true
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	members := make([]MemberState, 0, len(r.members))
+	for _, m := range r.members {
+		m.Connected = connected[m.UserID]
+		members = append(members, m)
+	}
+	return members
+}
 
+func (r *RoomHub) Register(c Conn) {
+	r.mu.Lock()
 	if old, ok := r.conns[c.UserID()]; ok {
 		_ = old.Close()
 	}
-
 	r.conns[c.UserID()] = c
+	r.connectedSince[c.UserID()] = time.Now()
 	r.lastActivity = time.Now()
+	r.mu.Unlock()
+
+	r.setPresence(c.UserID(), true)
 }
 
-func (r *RoomHub) Unregister(userID string) {
+// Unregister removes c from the room unless it has already been replaced by a
+// newer connection of the same user. It reports whether c was removed.
+func (r *RoomHub) Unregister(c Conn) bool {
 	r.mu.Lock()
-	defer r.mu.Unlock()
-	delete(r.conns, userID)
 	r.lastActivity = time.Now()
+	if cur, ok := r.conns[c.UserID()]; !ok || cur != c {
+		r.mu.Unlock()
+		return false
+	}
+	delete(r.conns, c.UserID())
+	delete(r.connectedSince, c.UserID())
+	r.mu.Unlock()
+
+	r.setPresence(c.UserID(), false)
+	return true
 }
 
 func (r *RoomHub) BroadcastPresence() {
 	r.mu.Lock()
-	members := make([]MemberState, 0, len(r.members))
-	for _, m := range r.members {
-		members = append(members, m)
-	}
-	conns := make([]Conn, 0, len(r.conns))
-	for _, c := range r.conns {
-		conns = append(conns, c)
-	}
+	hostPlays := r.hostPlays
 	r.mu.Unlock()
 
-	msg := map[string]any{
+	r.Broadcast(map[string]any{
 		"type": "room:presence",
 		"payload": map[string]any{
-			"code":    r.code,
-			"members": members,
+			"code":      r.code,
+			"members":   r.MemberStates(),
+			"hostPlays": hostPlays,
+			"pool":      r.PoolState(),
 		},
+	})
+}
+
+// PoolState compares the characters left in the room's pool with the connected
+// members who would get one, so the host can tell whether a round can start.
+// It is nil until the pool has been loaded.
+func (r *RoomHub) PoolState() map[string]any {
+	connected := r.ConnectedUserIDs()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.pool == nil {
+		return nil
 	}
+	players := 0
+	for _, uid := range connected {
+		role := r.members[uid].Role
+		if role == "spectator" || (role == "host" && !r.hostPlays) {
+			continue
+		}
+		players++
+	}
+	return map[string]any{
+		"lang":           r.pool.Lang,
+		"translatedOnly": r.pool.TranslatedOnly,
+		"remaining":      r.pool.Remaining,
+		"packs":          r.pool.Packs,
+		"players":        players,
+		"enough":         r.pool.Remaining >= players,
+	}
+}
+
+// Broadcast sends msg to everyone in the room, on this and every other instance.
+func (r *RoomHub) Broadcast(msg any) {
+	msg, seq := r.stamp("", msg)
+
+	r.mu.Lock()
+	conns := make([]Conn, 0, len(r.conns))
+	for _, c := range r.conns {
+		conns = append(conns, c)
+	}
+	r.lastActivity = time.Now()
+	r.mu.Unlock()
 
 	for _, c := range conns {
 		_ = c.Send(msg)
 	}
+	r.publish(seq, "", msg)
+}
 
+// ConnectedUserIDs returns the users connected to the room on any instance.
+func (r *RoomHub) ConnectedUserIDs() []string {
 	r.mu.Lock()
-	r.lastActivity = time.Now()
+	seen := make(map[string]bool, len(r.conns))
+	ids := make([]string, 0, len(r.conns))
+	for uid := range r.conns {
+		seen[uid] = true
+		ids = append(ids, uid)
+	}
 	r.mu.Unlock()
+
+	if r.bp != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		remote, err := r.bp.Presence(ctx, r.code)
+		cancel()
+		if err != nil {
+			log.Warn().Str("room", r.code).Err(err).Msg("ws: failed to read presence from backplane")
+		}
+		for _, uid := range remote {
+			if !seen[uid] {
+				seen[uid] = true
+				ids = append(ids, uid)
+			}
+		}
+	}
+	return ids
+}
+
+// ConnectedWithRole returns the connected members (on any instance) that have role.
+func (r *RoomHub) ConnectedWithRole(role string) []string {
+	ids := r.ConnectedUserIDs()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := ids[:0]
+	for _, uid := range ids {
+		if r.members[uid].Role == role {
+			out = append(out, uid)
+		}
+	}
+	return out
 }
 
+// SendTo delivers a private message to userID. The message is kept in the replay
+// buffer of every instance so it can be re-delivered if the user resumes after
+// missing it, even through another instance.
 func (r *RoomHub) SendTo(userID string, msg any) {
+	msg, seq := r.stamp(userID, msg)
+
 	r.mu.Lock()
 	c := r.conns[userID]
 	r.mu.Unlock()
+
 	if c != nil {
 		_ = c.Send(msg)
 	}
+	r.publish(seq, userID, msg)
 }
 
 func (h *Hub) RoomSnapshot() map[string]*RoomHub {
@@ -145,6 +339,9 @@ func (h *Hub) RoomSnapshot() map[string]*RoomHub {
 func (h *Hub) DeleteRoom(code string) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
+	if r, ok := h.rooms[code]; ok {
+		r.detach()
+	}
 	delete(h.rooms, code)
 }
 
@@ -162,6 +359,7 @@ func (h *Hub) TryDeleteEmptyRoom(code string) bool {
 	r.mu.Unlock()
 
 	if empty {
+		r.detach()
 		delete(h.rooms, code)
 		return true
 	}
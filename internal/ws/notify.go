@@ -0,0 +1,77 @@
+package ws
+
+import (
+	"context"
+
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/storage"
+)
+
+// The methods below let the HTTP API push its changes into live rooms. Events
+// reach every instance through the hub's backplane.
+
+// PacksChanged tells the room its pack selection was replaced.
+func (h *Handler) PacksChanged(ctx context.Context, code string, packSlugs []string) {
+	room, ok := h.Hub.EventTarget(code)
+	if !ok {
+		return
+	}
+	room.Broadcast(map[string]any{
+		"type": "room:packs_changed",
+		"payload": map[string]any{
+			"code":      code,
+			"packSlugs": packSlugs,
+		},
+	})
+
+	room.mu.Lock()
+	roomID := room.roomID
+	room.mu.Unlock()
+	if roomID != "" {
+		refreshPool(ctx, h.Store, room, roomID)
+		room.BroadcastPresence()
+	}
+}
+
+// MemberJoined announces a member that joined without a socket, e.g. through
+// POST /v1/rooms/join.
+func (h *Handler) MemberJoined(ctx context.Context, code, roomID string, m storage.RoomMember) {
+	room, ok := h.Hub.EventTarget(code)
+	if !ok {
+		return
+	}
+	syncMembers(ctx, h.Store, room, roomID)
+	room.Broadcast(map[string]any{
+		"type": "room:member_joined",
+		"payload": map[string]any{
+			"code":        code,
+			"userId":      m.UserID,
+			"displayName": m.DisplayName,
+			"role":        m.Role,
+		},
+	})
+	room.BroadcastPresence()
+}
+
+// SettingsChanged broadcasts the room's new settings.
+func (h *Handler) SettingsChanged(ctx context.Context, code, roomID string, settings storage.RoomSettings) {
+	room, ok := h.Hub.EventTarget(code)
+	if !ok {
+		return
+	}
+	publishSettings(ctx, h.Store, room, roomID, settings)
+}
+
+// ConnectedUsers returns the users connected to the room on any instance.
+func (h *Handler) ConnectedUsers(ctx context.Context, code string) []string {
+	return h.Hub.Presence(ctx, code)
+}
+
+// publishSettings refreshes the room's cached state and broadcasts settings.
+func publishSettings(ctx context.Context, store *storage.Storage, room *RoomHub, roomID string, settings storage.RoomSettings) {
+	syncMembers(ctx, store, room, roomID)
+	room.Broadcast(map[string]any{
+		"type":    "room:settings",
+		"payload": map[string]any{"settings": settings},
+	})
+	room.BroadcastPresence()
+}
@@ -0,0 +1,68 @@
+package ws
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/storage"
+)
+
+type RoundTimerConfig struct {
+	Tick  time.Duration // 1s
+	Batch int           // max rounds ended per tick
+}
+
+// StartRoundTimer ends timed rounds once their deadline passes and broadcasts
+// round:ended. Deadlines are read from the database, so rounds started before
+// a restart still end on time.
+func StartRoundTimer(h *Hub, store *storage.Storage, cfg RoundTimerConfig) func() {
+	if cfg.Batch <= 0 {
+		cfg.Batch = 50
+	}
+	stop := make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(cfg.Tick)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				ended, err := store.EndExpiredRounds(ctx, cfg.Batch)
+				cancel()
+				if err != nil {
+					log.Error().Err(err).Msg("round timer: failed to end expired rounds")
+					continue
+				}
+
+				for _, er := range ended {
+					log.Info().Str("room", er.RoomCode).Str("round", er.RoundID).Msg("round timer: round ended")
+
+					// The game must complete even when no one in the room is
+					// connected to this instance; only the broadcasts need the room.
+					ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+					g, err := store.CompleteGameIfDone(ctx, er.RoomID)
+					if err != nil {
+						log.Error().Str("room", er.RoomCode).Err(err).Msg("round timer: failed to check game completion")
+					}
+
+					if room, ok := h.EventTarget(er.RoomCode); ok {
+						publishRoundEnded(ctx, store, room, er.RoundID, "timer", er.Claims)
+						if g != nil {
+							publishGameEnded(ctx, store, room, g, gameEndReason(g))
+						}
+						room.BroadcastPresence()
+					}
+					cancel()
+				}
+
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return func() { close(stop) }
+}
@@ -0,0 +1,108 @@
+package ws
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/storage"
+)
+
+// roundAssignedMsg builds the private round:assigned message for userID: every
+// other player's character, but never their own.
+func roundAssignedMsg(roundID string, assigns []storage.RoundAssignment, userID string) map[string]any {
+	return map[string]any{
+		"type": "round:assigned",
+		"payload": map[string]any{
+			"roundId":     roundID,
+			"assignments": assignmentsExcept(assigns, userID),
+		},
+	}
+}
+
+// roundStartedMsg announces a new round to the whole room without revealing
+// any character. endsAt is nil for untimed rounds.
+func roundStartedMsg(roundID, lang string, endsAt *time.Time, assigns []storage.RoundAssignment) map[string]any {
+	players := make([]string, 0, len(assigns))
+	for _, a := range assigns {
+		players = append(players, a.UserID)
+	}
+	return map[string]any{
+		"type": "round:started",
+		"payload": map[string]any{
+			"roundId":       roundID,
+			"lang":          lang,
+			"endsAt":        endsAt,
+			"playerUserIds": players,
+		},
+	}
+}
+
+// roundEndsAtMsg gives clients the deadline of a timed round. serverTime lets
+// them correct for clock skew when rendering the countdown.
+func roundEndsAtMsg(roundID string, endsAt time.Time) map[string]any {
+	return map[string]any{
+		"type": "round:ends_at",
+		"payload": map[string]any{
+			"roundId":    roundID,
+			"endsAt":     endsAt,
+			"serverTime": time.Now().UTC(),
+		},
+	}
+}
+
+// publishRoundEnded tells the room a round is over and reveals every player's
+// character, who guessed it and the round's score changes. Claims the round end
+// decided are announced first so clients close them. reason is "host" or
+// "timer".
+func publishRoundEnded(ctx context.Context, store *storage.Storage, room *RoomHub, roundID, reason string, claims []storage.ExpiredClaim) {
+	for _, c := range claims {
+		publishClaimResolution(ctx, store, room, c.Claim, c.Tally, c.Deltas)
+	}
+
+	payload := map[string]any{
+		"roundId": roundID,
+		"reason":  reason,
+	}
+
+	summary, err := store.GetRoundSummary(ctx, roundID)
+	if err != nil {
+		log.Error().Str("room", room.code).Str("round", roundID).Err(err).Msg("ws: failed to load round summary")
+	} else {
+		payload["results"] = summary.Results
+		payload["scoreDeltas"] = summary.ScoreDeltas
+	}
+
+	room.Broadcast(map[string]any{
+		"type":    "round:ended",
+		"payload": payload,
+	})
+}
+
+// visibleAssignments returns the assignments a member with role may see.
+// Spectators only see characters when the room lets them; everyone else sees
+// every character but their own.
+func visibleAssignments(assigns []storage.RoundAssignment, userID, role string, settings storage.RoomSettings) []map[string]any {
+	if role == "spectator" && !settings.SpectatorsSeeAll {
+		return []map[string]any{}
+	}
+	return assignmentsExcept(assigns, userID)
+}
+
+func assignmentsExcept(assigns []storage.RoundAssignment, userID string) []map[string]any {
+	out := make([]map[string]any, 0, len(assigns))
+	for _, a := range assigns {
+		if a.UserID == userID {
+			continue
+		}
+		out = append(out, map[string]any{
+			"userId": a.UserID,
+			"character": map[string]any{
+				"id":   a.Character.ID,
+				"name": a.Character.Name,
+			},
+		})
+	}
+	return out
+}
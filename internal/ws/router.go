@@ -0,0 +1,103 @@
+package ws
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/apierr"
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/authz"
+)
+
+// HandlerFunc handles one inbound message type. Returned errors are mapped to
+// the apierr catalogue and sent back to the client; unmapped errors are logged
+// and reported as INTERNAL.
+type HandlerFunc func(ctx context.Context, s *Session, env Envelope) error
+
+type Middleware func(HandlerFunc) HandlerFunc
+
+type Router struct {
+	handlers   map[string]HandlerFunc
+	middleware []Middleware
+}
+
+func NewRouter() *Router {
+	return &Router{handlers: map[string]HandlerFunc{}}
+}
+
+// Use adds middleware applied to every handler registered afterwards.
+func (r *Router) Use(mw ...Middleware) {
+	r.middleware = append(r.middleware, mw...)
+}
+
+func (r *Router) Register(msgType string, h HandlerFunc) {
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		h = r.middleware[i](h)
+	}
+	r.handlers[msgType] = h
+}
+
+// Dispatch runs the handler registered for env.Type and sends a uniform error
+// reply when it fails.
+func (r *Router) Dispatch(ctx context.Context, s *Session, env Envelope) {
+	h, ok := r.handlers[env.Type]
+	if !ok {
+		log.Debug().Str("user", s.UserID).Str("type", env.Type).Msg("ws: unknown message type, ignoring")
+		return
+	}
+
+	err := h(ctx, s, env)
+	if err == nil {
+		return
+	}
+
+	e := apierr.From(err)
+	if e.Code == apierr.Internal {
+		log.Error().Str("user", s.UserID).Str("room", s.RoomCode).Str("type", env.Type).Err(err).Msg("ws: handler failed")
+	}
+	_ = s.Send(errorMsg(e, env.RequestID))
+}
+
+func errorMsg(e *apierr.Error, requestID string) map[string]any {
+	cp := *e
+	cp.RequestID = requestID
+	m := map[string]any{"type": "error", "payload": &cp}
+	addRequestID(m, requestID)
+	return m
+}
+
+func requireJoined(next HandlerFunc) HandlerFunc {
+	return func(ctx context.Context, s *Session, env Envelope) error {
+		if !s.Joined() {
+			return apierr.New(apierr.NotJoined, "must join first")
+		}
+		return next(ctx, s, env)
+	}
+}
+
+// requireHost checks room ownership in the database rather than the role the
+// session joined with, so a host transfer takes effect on every instance at once.
+func (h *Handler) requireHost(next HandlerFunc) HandlerFunc {
+	return requireJoined(func(ctx context.Context, s *Session, env Envelope) error {
+		dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+		_, err := authz.Room(dbCtx, h.Store, s.RoomCode, s.UserID, authz.Owner)
+		cancel()
+		if err != nil {
+			return err
+		}
+		s.Role = "host"
+		return next(ctx, s, env)
+	})
+}
+
+func rateLimit(perSecond float64, burst int) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, s *Session, env Envelope) error {
+			if !s.limiter.allow(time.Now(), perSecond, burst) {
+				return apierr.New(apierr.RateLimited, "too many messages")
+			}
+			return next(ctx, s, env)
+		}
+	}
+}
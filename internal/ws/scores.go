@@ -0,0 +1,86 @@
+package ws
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/storage"
+)
+
+// syncMembers replaces the room's presence state with the member rows (scores,
+// roles, names) and settings in the database, keeping the connected flag of
+// local sockets.
+func syncMembers(ctx context.Context, store *storage.Storage, room *RoomHub, roomID string) {
+	members, err := store.ListRoomMembers(ctx, roomID)
+	if err != nil {
+		log.Error().Str("room", room.code).Err(err).Msg("ws: failed to load room members")
+		return
+	}
+	settings, err := store.GetRoomSettings(ctx, roomID)
+	if err != nil {
+		log.Error().Str("room", room.code).Err(err).Msg("ws: failed to load room settings")
+		settings = storage.DefaultRoomSettings()
+	}
+	setPool(ctx, store, room, roomID, settings)
+
+	room.mu.Lock()
+	defer room.mu.Unlock()
+	room.roomID = roomID
+	room.hostPlays = settings.HostPlays
+	room.members = make(map[string]MemberState, len(members))
+	for _, m := range members {
+		_, ok := room.conns[m.UserID]
+		room.members[m.UserID] = MemberState{
+			UserID:      m.UserID,
+			DisplayName: m.DisplayName,
+			Role:        m.Role,
+			Score:       m.Score,
+			Connected:   ok,
+		}
+	}
+}
+
+// refreshPool reloads the room's character pool for its current language
+// settings. Call it whenever characters are used up or released.
+func refreshPool(ctx context.Context, store *storage.Storage, room *RoomHub, roomID string) {
+	settings, err := store.GetRoomSettings(ctx, roomID)
+	if err != nil {
+		log.Error().Str("room", room.code).Err(err).Msg("ws: failed to load room settings")
+		return
+	}
+	setPool(ctx, store, room, roomID, settings)
+}
+
+func setPool(ctx context.Context, store *storage.Storage, room *RoomHub, roomID string, settings storage.RoomSettings) {
+	pool, err := store.GetRoomPool(ctx, roomID, settings.Lang, settings.TranslatedOnly)
+	if err != nil {
+		log.Error().Str("room", room.code).Err(err).Msg("ws: failed to load character pool")
+		return
+	}
+	room.mu.Lock()
+	room.pool = pool
+	room.mu.Unlock()
+}
+
+func scoreChangedMsg(claimID string, deltas []storage.ScoreDelta) map[string]any {
+	payload := map[string]any{"deltas": deltas}
+	if claimID != "" {
+		payload["claimId"] = claimID
+	}
+	return map[string]any{
+		"type":    "score:changed",
+		"payload": payload,
+	}
+}
+
+// publishClaimResolution broadcasts a resolved claim and any score changes it produced.
+func publishClaimResolution(ctx context.Context, store *storage.Storage, room *RoomHub, c *storage.Claim, t storage.ClaimTally, deltas []storage.ScoreDelta) {
+	room.Broadcast(claimResolvedMsg(c, t))
+	if len(deltas) == 0 {
+		return
+	}
+	syncMembers(ctx, store, room, c.RoomID)
+	room.Broadcast(scoreChangedMsg(c.ID, deltas))
+	room.BroadcastPresence()
+}
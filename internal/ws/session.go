@@ -0,0 +1,116 @@
+package ws
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+// Sender is the outbound side of a client connection.
+type Sender interface {
+	Send(v any) error
+}
+
+// rawSender writes directly to a socket that has not joined a room yet and
+// therefore has no WSConn write loop.
+type rawSender struct {
+	ctx context.Context
+	c   *websocket.Conn
+}
+
+func (r rawSender) Send(v any) error {
+	return r.c.Write(r.ctx, websocket.MessageText, Marshal(v))
+}
+
+// Session holds the per-connection state shared by all message handlers.
+type Session struct {
+	UserID string
+
+	RoomCode    string
+	RoomID      string
+	Role        string
+	DisplayName string
+
+	Room *RoomHub
+	Conn Conn
+
+	out     Sender
+	socket  *websocket.Conn
+	limiter tokenBucket
+}
+
+// NewSession creates a session that replies through out until it joins a room.
+func NewSession(userID string, out Sender) *Session {
+	return &Session{UserID: userID, out: out}
+}
+
+func (s *Session) Joined() bool {
+	return s.Room != nil && s.Conn != nil
+}
+
+// Attach binds the session to a room connection; replies go through the
+// connection's write loop from then on.
+func (s *Session) Attach(room *RoomHub, conn Conn) {
+	s.Room = room
+	s.Conn = conn
+	s.out = conn
+}
+
+// connect returns the room connection for this socket, creating it on first use.
+func (s *Session) connect(role, name string) Conn {
+	if wc, ok := s.Conn.(*WSConn); ok {
+		wc.setIdentity(role, name)
+		return wc
+	}
+	return NewWSConn(s.socket, s.UserID, role, name)
+}
+
+func (s *Session) Send(v any) error {
+	return s.out.Send(v)
+}
+
+// Reply sends a message of the given type that echoes env's requestId.
+func (s *Session) Reply(env Envelope, msgType string, payload any) error {
+	m := map[string]any{"type": msgType}
+	if payload != nil {
+		m["payload"] = payload
+	}
+	addRequestID(m, env.RequestID)
+	return s.Send(m)
+}
+
+func (s *Session) Touch() {
+	if t, ok := s.Conn.(interface{ Touch() }); ok {
+		t.Touch()
+	}
+}
+
+// tokenBucket is a small per-session rate limiter.
+type tokenBucket struct {
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func (b *tokenBucket) allow(now time.Time, perSecond float64, burst int) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.last.IsZero() {
+		b.tokens = float64(burst)
+	} else {
+		b.tokens += now.Sub(b.last).Seconds() * perSecond
+		if b.tokens > float64(burst) {
+			b.tokens = float64(burst)
+		}
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
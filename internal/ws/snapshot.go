@@ -0,0 +1,86 @@
+package ws
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/JsotoSoftware/guess-who-game-backend/internal/storage"
+)
+
+// buildSnapshot assembles everything a client needs to render the room from
+// scratch, as seen by userID joined with role.
+func buildSnapshot(ctx context.Context, store *storage.Storage, room *RoomHub, roomObj *storage.Room, userID, role string) (map[string]any, error) {
+	packs, err := store.GetRoomSelectedPackSlugs(ctx, roomObj.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := store.GetRoomSettings(ctx, roomObj.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	setPool(ctx, store, room, roomObj.ID, settings)
+
+	game, err := store.GetActiveGame(ctx, roomObj.ID)
+	if err != nil && err != pgx.ErrNoRows {
+		return nil, err
+	}
+
+	var round map[string]any
+	if roomObj.CurrentRoundID != nil && *roomObj.CurrentRoundID != "" {
+		roundID := *roomObj.CurrentRoundID
+
+		assigns, err := store.ListRoundAssignments(ctx, roundID)
+		if err != nil {
+			return nil, err
+		}
+		roundInfo, err := store.GetRound(ctx, roundID)
+		if err != nil {
+			return nil, err
+		}
+
+		claim, tally, err := store.GetOpenClaim(ctx, roomObj.ID)
+		if err != nil {
+			return nil, err
+		}
+		var openClaim map[string]any
+		if claim != nil {
+			openClaim = map[string]any{
+				"claimId":        claim.ID,
+				"claimantUserId": claim.ClaimantUserID,
+				"endsAt":         claim.EndsAt,
+				"eligibleVoters": eligibleVoters(room, claim.ClaimantUserID),
+				"tally":          tally,
+			}
+		}
+
+		round = map[string]any{
+			"roundId":     roundID,
+			"lang":        roundInfo.Lang,
+			"endsAt":      roundInfo.EndsAt,
+			"assignments": visibleAssignments(assigns, userID, role, settings),
+			"openClaim":   openClaim,
+		}
+	}
+
+	epoch, seq := room.Cursor()
+	return map[string]any{
+		"type": "room:snapshot",
+		"payload": map[string]any{
+			"code":           roomObj.Code,
+			"roomId":         roomObj.ID,
+			"ownerUserId":    roomObj.OwnerUserID,
+			"epoch":          epoch,
+			"seq":            seq,
+			"members":        room.MemberStates(),
+			"packs":          packs,
+			"pool":           room.PoolState(),
+			"currentRoundId": roomObj.CurrentRoundID,
+			"round":          round,
+			"game":           game,
+			"settings":       settings,
+		},
+	}, nil
+}
@@ -37,6 +37,7 @@ func StartSweeper(h *Hub, cfg SweeperConfig) func() {
 						}
 						toClose = append(toClose, c)
 						delete(r.conns, uid)
+						delete(r.connectedSince, uid)
 						if m, ok := r.members[uid]; ok {
 							m.Connected = false
 							r.members[uid] = m
@@ -50,7 +51,9 @@ func StartSweeper(h *Hub, cfg SweeperConfig) func() {
 
 					for _, c := range toClose {
 						_ = c.Close()
+						r.setPresence(c.UserID(), false)
 					}
+					r.RefreshPresence()
 					if len(toClose) > 0 {
 						r.BroadcastPresence()
 					}
@@ -0,0 +1,6 @@
+-- Per-room settings (scoring policy, etc.) stored as a typed JSON document
+CREATE TABLE IF NOT EXISTS room_settings (
+  room_id UUID PRIMARY KEY REFERENCES rooms(id) ON DELETE CASCADE,
+  settings JSONB NOT NULL DEFAULT '{}'::jsonb,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
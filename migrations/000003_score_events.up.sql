@@ -0,0 +1,24 @@
+-- Score ledger: every change to room_members.score is recorded here
+CREATE TABLE IF NOT EXISTS score_events (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+  round_id UUID NULL REFERENCES room_rounds(id) ON DELETE SET NULL,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  delta INT NOT NULL,
+  reason TEXT NOT NULL,
+  actor_user_id UUID NULL,                 -- NULL when applied by the server (scoring policy)
+  claim_id UUID NULL REFERENCES round_claims(id) ON DELETE SET NULL,
+  reverts_event_id UUID NULL REFERENCES score_events(id) ON DELETE SET NULL,
+  reverted_at TIMESTAMPTZ NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp()
+);
+
+CREATE INDEX IF NOT EXISTS idx_score_events_room_created ON score_events(room_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_score_events_round_id ON score_events(round_id);
+
+-- Seed the ledger with scores accumulated before it existed
+-- ('initial' is storage.ReasonInitial; these entries cannot be undone)
+INSERT INTO score_events (room_id, user_id, delta, reason)
+SELECT room_id, user_id, score, 'initial'
+FROM room_members
+WHERE score <> 0;
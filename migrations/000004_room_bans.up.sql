@@ -0,0 +1,9 @@
+-- Users banned from a room by its host; checked on every join path
+CREATE TABLE IF NOT EXISTS room_bans (
+  room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  banned_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  reason TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (room_id, user_id)
+);
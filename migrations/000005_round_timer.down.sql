@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_room_rounds_ends_at_open;
+ALTER TABLE room_rounds DROP COLUMN IF EXISTS ends_at;
@@ -0,0 +1,7 @@
+-- Optional deadline for timed rounds; the server ends the round once it passes
+ALTER TABLE room_rounds
+  ADD COLUMN IF NOT EXISTS ends_at TIMESTAMPTZ NULL;
+
+CREATE INDEX IF NOT EXISTS idx_room_rounds_ends_at_open
+  ON room_rounds(ends_at)
+  WHERE ended_at IS NULL AND ends_at IS NOT NULL;
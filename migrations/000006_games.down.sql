@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_score_events_game_id;
+DROP INDEX IF EXISTS idx_room_rounds_game_id;
+ALTER TABLE score_events DROP COLUMN IF EXISTS game_id;
+ALTER TABLE room_rounds DROP COLUMN IF EXISTS game_id;
+DROP TABLE IF EXISTS games;
@@ -0,0 +1,24 @@
+-- Games: a match of several rounds inside a room, with scores scoped to it
+CREATE TABLE IF NOT EXISTS games (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+  status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active','ended')),
+  target_rounds INT NULL,                  -- end after this many rounds
+  target_score INT NULL,                   -- end at the first round end where someone reaches it
+  started_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+  started_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  ended_at TIMESTAMPTZ NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_active_game_per_room
+  ON games(room_id)
+  WHERE status = 'active';
+
+ALTER TABLE room_rounds
+  ADD COLUMN IF NOT EXISTS game_id UUID NULL REFERENCES games(id) ON DELETE SET NULL;
+
+ALTER TABLE score_events
+  ADD COLUMN IF NOT EXISTS game_id UUID NULL REFERENCES games(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_room_rounds_game_id ON room_rounds(game_id);
+CREATE INDEX IF NOT EXISTS idx_score_events_game_id ON score_events(game_id);
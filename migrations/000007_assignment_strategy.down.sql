@@ -0,0 +1,8 @@
+ALTER TABLE room_rounds
+  DROP COLUMN IF EXISTS assignment_params,
+  DROP COLUMN IF EXISTS assignment_candidates,
+  DROP COLUMN IF EXISTS assignment_players,
+  DROP COLUMN IF EXISTS assignment_seed,
+  DROP COLUMN IF EXISTS assignment_strategy;
+
+ALTER TABLE characters DROP COLUMN IF EXISTS difficulty;
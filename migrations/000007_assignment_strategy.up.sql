@@ -0,0 +1,13 @@
+-- Optional character difficulty (1 easy, 2 medium, 3 hard) for weighted assignment
+ALTER TABLE characters
+  ADD COLUMN IF NOT EXISTS difficulty SMALLINT NULL CHECK (difficulty BETWEEN 1 AND 3);
+
+-- Everything a round's draw depended on, so it can be replayed: the strategy,
+-- its seed, the players and candidates in the order they were fed in, and the
+-- strategy options (difficulty, pack balance, characters already seen).
+ALTER TABLE room_rounds
+  ADD COLUMN IF NOT EXISTS assignment_strategy TEXT NULL,
+  ADD COLUMN IF NOT EXISTS assignment_seed BIGINT NULL,
+  ADD COLUMN IF NOT EXISTS assignment_players UUID[] NULL,
+  ADD COLUMN IF NOT EXISTS assignment_candidates UUID[] NULL,
+  ADD COLUMN IF NOT EXISTS assignment_params JSONB NULL;